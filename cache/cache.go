@@ -0,0 +1,220 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/flowControl"
+	"github.com/f1gopher/f1gopherlib/parser"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry is the data downloaded for one session
+type Entry struct {
+	Event f1gopherlib.RaceEvent
+	Path  string
+	Size  int64
+	// When the newest file was downloaded
+	Modified time.Time
+}
+
+// Path is the folder F1GopherLib caches the data for a session in
+func Path(dir string, event f1gopherlib.RaceEvent) string {
+	return filepath.Join(dir, fmt.Sprintf("%d", event.RaceTime.Year()),
+		fmt.Sprintf("%s_%s", event.RaceTime.Format("2006-01-02"), event.Name), event.Type.String())
+}
+
+// Name is the season, country, event and session
+func Name(event f1gopherlib.RaceEvent) string {
+	return fmt.Sprintf("%d %s - %s - %s", event.RaceTime.Year(), event.Country, event.Name, event.Type.String())
+}
+
+// Scan finds the sessions that have data in the cache, most recently downloaded first
+func Scan(dir string, events []f1gopherlib.RaceEvent) ([]Entry, error) {
+	var entries []Entry
+
+	for _, event := range events {
+		entry := Entry{Event: event, Path: Path(dir, event)}
+
+		err := filepath.WalkDir(entry.Path, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			entry.Size += info.Size()
+			if info.ModTime().After(entry.Modified) {
+				entry.Modified = info.ModTime()
+			}
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if entry.Size > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Modified.After(entries[j].Modified)
+	})
+	return entries, nil
+}
+
+// Total is the size of all the entries
+func Total(entries []Entry) int64 {
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	return total
+}
+
+// Remove deletes the data for a session along with the folders above it that are left empty
+func Remove(dir string, entry Entry) error {
+	if err := os.RemoveAll(entry.Path); err != nil {
+		return err
+	}
+
+	// Weekend and then season folder, removing a folder that isn't empty fails which is fine
+	parent := filepath.Dir(entry.Path)
+	for x := 0; x < 2 && parent != filepath.Clean(dir); x++ {
+		if os.Remove(parent) != nil {
+			break
+		}
+		parent = filepath.Dir(parent)
+	}
+	return nil
+}
+
+// Trim removes the least recently downloaded sessions until the cache is no bigger than the limit and returns
+// what was removed. A zero limit means there is no limit.
+func Trim(dir string, entries []Entry, limit int64) ([]Entry, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var removed []Entry
+	total := Total(entries)
+	for x := len(entries) - 1; x >= 0 && total > limit; x-- {
+		if err := Remove(dir, entries[x]); err != nil {
+			return removed, err
+		}
+		total -= entries[x].Size
+		removed = append(removed, entries[x])
+	}
+	return removed, nil
+}
+
+// Download fetches the data for a session into the cache so it can be replayed offline. Team radio is only
+// downloaded when it is played.
+func Download(dir string, event f1gopherlib.RaceEvent) error {
+	data, err := f1gopherlib.CreateReplay(
+		parser.EventTime|parser.Timing|parser.Event|parser.RaceControl|parser.TeamRadio|parser.Weather,
+		event,
+		dir,
+		flowControl.StraightThrough)
+	if err != nil {
+		return err
+	}
+
+	// The data is downloaded when the replay is created. The replay blocks sending messages until they are read so
+	// read and throw them away until closing it has shut it down.
+	var wg sync.WaitGroup
+	discard(&wg, data.Weather())
+	discard(&wg, data.RaceControlMessages())
+	discard(&wg, data.Timing())
+	discard(&wg, data.Event())
+	discard(&wg, data.Telemetry())
+	discard(&wg, data.Location())
+	discard(&wg, data.Time())
+	discard(&wg, data.Radio())
+	discard(&wg, data.Drivers())
+	data.Close()
+	wg.Wait()
+
+	if _, err = os.Stat(Path(dir, event)); err != nil {
+		return fmt.Errorf("no data downloaded for %s", Name(event))
+	}
+	return nil
+}
+
+// discard reads messages until the channel is closed
+func discard[T any](wg *sync.WaitGroup, messages <-chan T) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range messages {
+		}
+	}()
+}
+
+var sizeUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"TB", 1 << 40},
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize reads a size like 500MB or 2.5GB, a number without a unit is bytes
+func ParseSize(value string) (int64, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.size
+			break
+		}
+	}
+
+	size, err := strconv.ParseFloat(value, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid size \"%s\", use a number followed by B, KB, MB, GB or TB", value)
+	}
+	return int64(size * float64(multiplier)), nil
+}
+
+// FormatSize is a size in the largest unit it has at least one of
+func FormatSize(size int64) string {
+	for _, unit := range sizeUnits {
+		if size >= unit.size && unit.size > 1 {
+			return fmt.Sprintf("%.1f %s", float64(size)/float64(unit.size), unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d B", size)
+}
@@ -0,0 +1,102 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cache
+
+import (
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var raceTime = time.Date(2023, 7, 9, 14, 0, 0, 0, time.UTC)
+
+func testEvent(session Messages.SessionType) f1gopherlib.RaceEvent {
+	return f1gopherlib.RaceEvent{Country: "Britain", Name: "British Grand Prix", RaceTime: raceTime, EventTime: raceTime, Type: session}
+}
+
+// cacheSession writes a file of the size into the cache folder for the session
+func cacheSession(t *testing.T, dir string, event f1gopherlib.RaceEvent, size int, modified time.Time) {
+	path := filepath.Join(Path(dir, event), "TimingData.jsonStream")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path, modified, modified)
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	race := testEvent(Messages.RaceSession)
+	qualifying := testEvent(Messages.QualifyingSession)
+	practice := testEvent(Messages.Practice1Session)
+
+	if Path(dir, race) != filepath.Join(dir, "2023", "2023-07-09_British Grand Prix", "Race") {
+		t.Errorf("unexpected cache path: %s", Path(dir, race))
+	}
+
+	cacheSession(t, dir, qualifying, 300, raceTime.Add(-time.Hour))
+	cacheSession(t, dir, race, 200, raceTime)
+
+	entries, err := Scan(dir, []f1gopherlib.RaceEvent{race, qualifying, practice})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].Event.Type != Messages.RaceSession || entries[0].Size != 200 || Total(entries) != 500 {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	// Removing the oldest gets under the limit
+	removed, err := Trim(dir, entries, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 1 || removed[0].Event.Type != Messages.QualifyingSession {
+		t.Errorf("expected qualifying to be removed, got %+v", removed)
+	}
+
+	// Removing the last session for the season removes the season folder
+	if err = Remove(dir, entries[0]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "2023")); !os.IsNotExist(err) {
+		t.Errorf("expected the season folder to be removed, got %v", err)
+	}
+	if _, err = os.Stat(dir); err != nil {
+		t.Errorf("expected the cache folder to be kept, got %v", err)
+	}
+}
+
+func TestSize(t *testing.T) {
+	for value, expected := range map[string]int64{"500": 500, "2KB": 2048, "1.5 gb": 3 << 29, "10MB": 10 << 20} {
+		if size, err := ParseSize(value); err != nil || size != expected {
+			t.Errorf("expected %d for %s, got %d %v", expected, value, size, err)
+		}
+	}
+	for _, value := range []string{"", "GB", "-1MB", "5XB"} {
+		if _, err := ParseSize(value); err == nil {
+			t.Errorf("expected an error for \"%s\"", value)
+		}
+	}
+
+	if FormatSize(1536) != "1.5 KB" || FormatSize(12) != "12 B" || FormatSize(3<<30) != "3.0 GB" {
+		t.Errorf("unexpected sizes: %s %s %s", FormatSize(1536), FormatSize(12), FormatSize(3<<30))
+	}
+}
@@ -0,0 +1,102 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"f1gopher/f1gopher-cmdline/cache"
+	"f1gopher/f1gopher-cmdline/menu"
+	"flag"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib"
+	"log"
+	"os"
+	"strings"
+)
+
+// download fetches the sessions matching each search into the cache so they can be replayed offline
+func download(args []string) {
+	flags := flag.NewFlagSet("download", flag.ExitOnError)
+	cachePtr := flags.String("cache", "./.cache", "Path to the folder to cache data in")
+	listPtr := flags.Bool("list", false, "List the matching sessions without downloading them")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s download [flags] <search>...\n\n", os.Args[0])
+		fmt.Fprintln(flags.Output(), "Downloads every session matching a search, searches work the same as in the replay menu.")
+		fmt.Fprintf(flags.Output(), "For example: %s download \"2023 race\" \"silverstone 2024\"\n\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	history := f1gopherlib.RaceHistory()
+	var events []f1gopherlib.RaceEvent
+	found := make(map[string]bool)
+	for _, search := range flags.Args() {
+		for _, event := range menu.FindEvents(history, search) {
+			path := cache.Path(*cachePtr, event)
+			if !found[path] {
+				found[path] = true
+				events = append(events, event)
+			}
+		}
+	}
+	if len(events) == 0 {
+		log.Fatalf("No sessions match %s", strings.Join(flags.Args(), ", "))
+	}
+
+	entries, err := cache.Scan(*cachePtr, events)
+	if err != nil {
+		log.Fatalf("Error reading the cache: %v", err)
+	}
+	cached := make(map[string]bool)
+	for _, entry := range entries {
+		cached[entry.Path] = true
+	}
+
+	failed := 0
+	for x, event := range events {
+		name := cache.Name(event)
+		isCached := cached[cache.Path(*cachePtr, event)]
+
+		if *listPtr {
+			if isCached {
+				name += " (downloaded)"
+			}
+			fmt.Println(name)
+			continue
+		}
+
+		if isCached {
+			fmt.Printf("[%d/%d] %s: already downloaded\n", x+1, len(events), name)
+			continue
+		}
+
+		fmt.Printf("[%d/%d] Downloading %s... ", x+1, len(events), name)
+		if err = cache.Download(*cachePtr, event); err != nil {
+			fmt.Printf("failed: %v\n", err)
+			failed++
+			continue
+		}
+		fmt.Println("done")
+	}
+
+	if failed > 0 {
+		log.Fatalf("%d of %d sessions failed to download", failed, len(events))
+	}
+}
@@ -0,0 +1,217 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package fakeSession provides a scriptable implementation of f1gopherlib.F1GopherLib that plays back a fixed
+// sequence of messages so session UIs can be driven deterministically from tests.
+package fakeSession
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sync"
+	"time"
+)
+
+type Session struct {
+	name         string
+	session      Messages.SessionType
+	timezone     *time.Location
+	sessionStart time.Time
+	track        string
+	trackYear    int
+	pitlaneTime  time.Duration
+
+	script    []any
+	processed chan struct{}
+
+	weather             chan Messages.Weather
+	raceControlMessages chan Messages.RaceControlMessage
+	timing              chan Messages.Timing
+	event               chan Messages.Event
+	telemetry           chan Messages.Telemetry
+	location            chan Messages.Location
+	eventTime           chan Messages.EventTime
+	radio               chan Messages.Radio
+	drivers             chan Messages.Drivers
+
+	lock               sync.Mutex
+	paused             bool
+	lapIncrements      int
+	timeIncrements     []time.Duration
+	sessionStartSkips  int
+	telemetrySelection []int
+}
+
+func New(session Messages.SessionType, name string, sessionStart time.Time) *Session {
+	return &Session{
+		name:         name,
+		session:      session,
+		timezone:     time.UTC,
+		sessionStart: sessionStart,
+		track:        name,
+
+		// Unbuffered so that each message has been taken by the receiver before the next one is sent
+		weather:             make(chan Messages.Weather),
+		raceControlMessages: make(chan Messages.RaceControlMessage),
+		timing:              make(chan Messages.Timing),
+		event:               make(chan Messages.Event),
+		telemetry:           make(chan Messages.Telemetry),
+		location:            make(chan Messages.Location),
+		eventTime:           make(chan Messages.EventTime),
+		radio:               make(chan Messages.Radio),
+		drivers:             make(chan Messages.Drivers),
+		processed:           make(chan struct{}),
+	}
+}
+
+// Add appends messages to the script. Only the message types from the Messages package that F1GopherLib
+// publishes are accepted.
+func (s *Session) Add(msgs ...any) *Session {
+	for _, msg := range msgs {
+		switch msg.(type) {
+		case Messages.Timing, Messages.Event, Messages.EventTime, Messages.RaceControlMessage,
+			Messages.Weather, Messages.Radio, Messages.Drivers, Messages.Telemetry, Messages.Location:
+			s.script = append(s.script, msg)
+		default:
+			panic(fmt.Sprintf("Unhandled fake session message type: %T", msg))
+		}
+	}
+	return s
+}
+
+// Play sends every scripted message, in order, on its channel and returns once they have all been processed
+// by the listener.
+func (s *Session) Play() {
+	for _, msg := range s.script {
+		switch msgType := msg.(type) {
+		case Messages.Timing:
+			s.timing <- msgType
+		case Messages.Event:
+			s.event <- msgType
+		case Messages.EventTime:
+			s.eventTime <- msgType
+		case Messages.RaceControlMessage:
+			s.raceControlMessages <- msgType
+		case Messages.Weather:
+			s.weather <- msgType
+		case Messages.Radio:
+			s.radio <- msgType
+		case Messages.Drivers:
+			s.drivers <- msgType
+		case Messages.Telemetry:
+			s.telemetry <- msgType
+		case Messages.Location:
+			s.location <- msgType
+		}
+
+		// Wait for the listener to finish handling the message before sending the next one
+		<-s.processed
+	}
+	s.script = nil
+}
+
+// Processed is called by the listener once it has finished handling a message.
+func (s *Session) Processed() {
+	s.processed <- struct{}{}
+}
+
+func (s *Session) SetTimezone(timezone *time.Location)  { s.timezone = timezone }
+func (s *Session) SetTrack(track string, year int)      { s.track, s.trackYear = track, year }
+func (s *Session) SetTimeLostInPitlane(d time.Duration) { s.pitlaneTime = d }
+
+func (s *Session) Name() string                     { return s.name }
+func (s *Session) Session() Messages.SessionType    { return s.session }
+func (s *Session) CircuitTimezone() *time.Location  { return s.timezone }
+func (s *Session) SessionStart() time.Time          { return s.sessionStart }
+func (s *Session) Track() string                    { return s.track }
+func (s *Session) TrackYear() int                   { return s.trackYear }
+func (s *Session) TimeLostInPitlane() time.Duration { return s.pitlaneTime }
+
+func (s *Session) Weather() <-chan Messages.Weather { return s.weather }
+func (s *Session) RaceControlMessages() <-chan Messages.RaceControlMessage {
+	return s.raceControlMessages
+}
+func (s *Session) Timing() <-chan Messages.Timing       { return s.timing }
+func (s *Session) Event() <-chan Messages.Event         { return s.event }
+func (s *Session) Telemetry() <-chan Messages.Telemetry { return s.telemetry }
+func (s *Session) Location() <-chan Messages.Location   { return s.location }
+func (s *Session) Time() <-chan Messages.EventTime      { return s.eventTime }
+func (s *Session) Radio() <-chan Messages.Radio         { return s.radio }
+func (s *Session) Drivers() <-chan Messages.Drivers     { return s.drivers }
+
+func (s *Session) SelectTelemetrySources(drivers []int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.telemetrySelection = drivers
+}
+
+// IncrementLap is counted unless paused, like a replay it does nothing while paused
+func (s *Session) IncrementLap() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.paused {
+		s.lapIncrements++
+	}
+}
+
+// IncrementTime is recorded unless paused, like a replay it does nothing while paused
+func (s *Session) IncrementTime(duration time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.paused {
+		s.timeIncrements = append(s.timeIncrements, duration)
+	}
+}
+
+func (s *Session) SkipToSessionStart() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.sessionStartSkips++
+}
+
+func (s *Session) TogglePause() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.paused = !s.paused
+}
+
+func (s *Session) IsPaused() bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.paused
+}
+
+// LapIncrements returns how many times IncrementLap has been called.
+func (s *Session) LapIncrements() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.lapIncrements
+}
+
+// TimeIncrements returns every duration passed to IncrementTime.
+func (s *Session) TimeIncrements() []time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]time.Duration(nil), s.timeIncrements...)
+}
+
+// SessionStartSkips returns how many times SkipToSessionStart has been called.
+func (s *Session) SessionStartSkips() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.sessionStartSkips
+}
+
+func (s *Session) Close() {}
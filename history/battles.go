@@ -0,0 +1,99 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"sort"
+	"time"
+)
+
+// Battle is a driver who has been close behind the same car for consecutive laps
+type Battle struct {
+	Driver int
+	Ahead  int
+	// Position of the driver at the end of their latest lap
+	Position int
+	Laps     int
+	// Gap to the car ahead at the end of the first and latest laps of the battle
+	StartGap time.Duration
+	Gap      time.Duration
+}
+
+// Trend is the average change in the gap each lap, negative when the driver behind is closing
+func (b Battle) Trend() time.Duration {
+	if b.Laps < 2 {
+		return 0
+	}
+	return (b.Gap - b.StartGap) / time.Duration(b.Laps-1)
+}
+
+// isBattleLap is true if the driver finished the lap within gap of the car ahead racing them. Laps into or out of
+// the pits and behind the safety car aren't a fight.
+func isBattleLap(lap Lap, gap time.Duration) bool {
+	return lap.Position > 1 && lap.TimeDiffToPositionAhead > 0 && lap.TimeDiffToPositionAhead <= gap &&
+		!lap.PitIn && !lap.PitOut && !lap.SafetyCar
+}
+
+// Battles returns the drivers who have been within gap of the same car ahead for at least minLaps consecutive laps
+// up to their latest lap, in position order
+func (h *History) Battles(gap time.Duration, minLaps int) []Battle {
+	all := h.All()
+
+	// Who was in each position at the end of each lap
+	positions := make(map[int]map[int]int)
+	for driver, laps := range all {
+		for _, lap := range laps {
+			if positions[lap.Number] == nil {
+				positions[lap.Number] = make(map[int]int)
+			}
+			positions[lap.Number][lap.Position] = driver
+		}
+	}
+
+	var result []Battle
+	for driver, laps := range all {
+		battle := Battle{Driver: driver}
+		for x := len(laps) - 1; x >= 0; x-- {
+			lap := laps[x]
+			if !isBattleLap(lap, gap) {
+				break
+			}
+
+			// Laps have to follow on from each other and be behind the same car
+			ahead, exists := positions[lap.Number][lap.Position-1]
+			if !exists || (battle.Laps > 0 && (ahead != battle.Ahead || lap.Number != laps[x+1].Number-1)) {
+				break
+			}
+
+			if battle.Laps == 0 {
+				battle.Ahead = ahead
+				battle.Position = lap.Position
+				battle.Gap = lap.TimeDiffToPositionAhead
+			}
+			battle.StartGap = lap.TimeDiffToPositionAhead
+			battle.Laps++
+		}
+
+		if battle.Laps > 0 && battle.Laps >= minLaps {
+			result = append(result, battle)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Position < result[j].Position
+	})
+	return result
+}
@@ -0,0 +1,74 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// WriteCSV writes every completed lap as CSV, one row per driver per lap with times in seconds
+func (h *History) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	err := writer.Write([]string{"Driver", "Lap", "Completed", "Time", "Sector1", "Sector2", "Sector3", "Tire",
+		"LapsOnTire", "Position", "GapToLeader", "Interval", "PitIn", "PitOut", "Pitstops", "SpeedTrap",
+		"SafetyCar", "Yellow"})
+	if err != nil {
+		return err
+	}
+
+	for _, number := range h.Drivers() {
+		for _, lap := range h.Laps(number) {
+			err = writer.Write([]string{
+				strconv.Itoa(number),
+				strconv.Itoa(lap.Number),
+				lap.Completed.Format(time.RFC3339Nano),
+				seconds(lap.Time),
+				seconds(lap.Sector1),
+				seconds(lap.Sector2),
+				seconds(lap.Sector3),
+				lap.Tire.String(),
+				strconv.Itoa(lap.LapsOnTire),
+				strconv.Itoa(lap.Position),
+				seconds(lap.GapToLeader),
+				seconds(lap.TimeDiffToPositionAhead),
+				strconv.FormatBool(lap.PitIn),
+				strconv.FormatBool(lap.PitOut),
+				strconv.Itoa(lap.Pitstops),
+				strconv.Itoa(lap.SpeedTrap),
+				strconv.FormatBool(lap.SafetyCar),
+				strconv.FormatBool(lap.Yellow),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func seconds(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,234 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Lap is a completed lap for a driver
+type Lap struct {
+	Number    int
+	Completed time.Time
+
+	Time    time.Duration
+	Sector1 time.Duration
+	Sector2 time.Duration
+	Sector3 time.Duration
+
+	Tire       Messages.TireType
+	LapsOnTire int
+
+	// Position and gaps when crossing the line at the end of the lap
+	Position                int
+	GapToLeader             time.Duration
+	TimeDiffToPositionAhead time.Duration
+
+	// The lap ended in the pitlane or started from the pitlane
+	PitIn  bool
+	PitOut bool
+	// Number of pitstops made by the end of the lap
+	Pitstops int
+
+	SpeedTrap int
+
+	// A safety car or virtual safety car was out during the lap
+	SafetyCar bool
+	// The track was yellow or red flagged during the lap
+	Yellow bool
+}
+
+type driverHistory struct {
+	laps []Lap
+
+	// State for the lap in progress
+	startedInPit bool
+	sawPitlane   bool
+	safetyCar    bool
+	yellow       bool
+}
+
+// History records each completed lap for every driver in a session. It is safe to use from multiple goroutines.
+type History struct {
+	lock    sync.RWMutex
+	drivers map[int]*driverHistory
+}
+
+func New() *History {
+	return &History{
+		drivers: make(map[int]*driverHistory),
+	}
+}
+
+// Clear removes all the recorded laps
+func (h *History) Clear() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.drivers = make(map[int]*driverHistory)
+}
+
+// Add updates the history with the latest timing for a driver and the event state at the time. A lap is recorded
+// each time the number of laps completed by the driver increases.
+func (h *History) Add(driver Messages.Timing, event Messages.Event) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	current, exists := h.drivers[driver.Number]
+	if !exists {
+		current = &driverHistory{
+			startedInPit: isInPit(driver.Location),
+		}
+		h.drivers[driver.Number] = current
+	}
+
+	if isInPit(driver.Location) {
+		current.sawPitlane = true
+	}
+	if event.SafetyCar != Messages.Clear {
+		current.safetyCar = true
+	}
+	if isYellow(event.TrackStatus) {
+		current.yellow = true
+	}
+
+	lastLap := 0
+	if len(current.laps) > 0 {
+		lastLap = current.laps[len(current.laps)-1].Number
+	}
+
+	if driver.Lap <= 0 || driver.Lap < lastLap {
+		return
+	}
+
+	if driver.Lap == lastLap {
+		// Times for the lap can arrive after the lap count changes so fill them in when they do
+		lap := &current.laps[len(current.laps)-1]
+		if lap.Time == 0 {
+			lap.Time = driver.LastLap
+		}
+		if lap.Sector3 == 0 {
+			lap.Sector3 = driver.Sector3
+		}
+		return
+	}
+
+	pitIn := driver.Location == Messages.Pitlane || (current.sawPitlane && !current.startedInPit)
+
+	current.laps = append(current.laps, Lap{
+		Number:                  driver.Lap,
+		Completed:               driver.Timestamp,
+		Time:                    driver.LastLap,
+		Sector1:                 driver.Sector1,
+		Sector2:                 driver.Sector2,
+		Sector3:                 driver.Sector3,
+		Tire:                    driver.Tire,
+		LapsOnTire:              driver.LapsOnTire,
+		Position:                driver.Position,
+		GapToLeader:             driver.GapToLeader,
+		TimeDiffToPositionAhead: driver.TimeDiffToPositionAhead,
+		PitIn:                   pitIn,
+		PitOut:                  current.startedInPit,
+		Pitstops:                driver.Pitstops,
+		SpeedTrap:               driver.SpeedTrap,
+		SafetyCar:               current.safetyCar,
+		Yellow:                  current.yellow,
+	})
+
+	// Start the next lap with the current track state
+	current.startedInPit = pitIn || isInPit(driver.Location)
+	current.sawPitlane = false
+	current.safetyCar = event.SafetyCar != Messages.Clear
+	current.yellow = isYellow(event.TrackStatus)
+}
+
+func isYellow(status Messages.FlagState) bool {
+	return status == Messages.YellowFlag || status == Messages.DoubleYellowFlag || status == Messages.RedFlag
+}
+
+func isInPit(location Messages.CarLocation) bool {
+	return location == Messages.Pitlane || location == Messages.PitOut
+}
+
+// Drivers returns the numbers of all the drivers with history in ascending order
+func (h *History) Drivers() []int {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	result := make([]int, 0, len(h.drivers))
+	for number := range h.drivers {
+		result = append(result, number)
+	}
+	sort.Ints(result)
+	return result
+}
+
+// Laps returns a copy of all the completed laps for a driver in lap order
+func (h *History) Laps(driverNumber int) []Lap {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	current, exists := h.drivers[driverNumber]
+	if !exists {
+		return nil
+	}
+	return append([]Lap{}, current.laps...)
+}
+
+// Lap returns a single completed lap for a driver
+func (h *History) Lap(driverNumber int, lap int) (Lap, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	current, exists := h.drivers[driverNumber]
+	if !exists {
+		return Lap{}, false
+	}
+
+	for _, recorded := range current.laps {
+		if recorded.Number == lap {
+			return recorded, true
+		}
+	}
+	return Lap{}, false
+}
+
+// LastLap returns the most recently completed lap for a driver
+func (h *History) LastLap(driverNumber int) (Lap, bool) {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	current, exists := h.drivers[driverNumber]
+	if !exists || len(current.laps) == 0 {
+		return Lap{}, false
+	}
+	return current.laps[len(current.laps)-1], true
+}
+
+// All returns a copy of the completed laps for every driver keyed by driver number
+func (h *History) All() map[int][]Lap {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+
+	result := make(map[int][]Lap, len(h.drivers))
+	for number, current := range h.drivers {
+		result[number] = append([]Lap{}, current.laps...)
+	}
+	return result
+}
@@ -0,0 +1,335 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"bytes"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"testing"
+	"time"
+)
+
+func timing(lap int, lastLap time.Duration, location Messages.CarLocation) Messages.Timing {
+	return Messages.Timing{
+		Number:    44,
+		Lap:       lap,
+		LastLap:   lastLap,
+		Sector1:   30 * time.Second,
+		Sector2:   40 * time.Second,
+		Sector3:   20 * time.Second,
+		Tire:      Messages.Medium,
+		Position:  2,
+		Location:  location,
+		SpeedTrap: 310,
+	}
+}
+
+func TestLapsAreRecorded(t *testing.T) {
+	h := New()
+	green := Messages.Event{TrackStatus: Messages.GreenFlag}
+
+	h.Add(timing(0, 0, Messages.OnTrack), green)
+	h.Add(timing(1, 91*time.Second, Messages.OnTrack), green)
+	// Updates during the next lap don't add laps
+	h.Add(timing(1, 91*time.Second, Messages.OnTrack), green)
+
+	// The lap time arrives after the lap count
+	h.Add(timing(2, 0, Messages.OnTrack), green)
+	h.Add(timing(2, 90*time.Second, Messages.OnTrack), green)
+
+	laps := h.Laps(44)
+	if len(laps) != 2 {
+		t.Fatalf("expected 2 laps, got %v", laps)
+	}
+	if laps[0].Number != 1 || laps[0].Time != 91*time.Second || laps[0].SpeedTrap != 310 {
+		t.Errorf("unexpected first lap: %+v", laps[0])
+	}
+	if laps[1].Time != 90*time.Second {
+		t.Errorf("expected the late lap time to be recorded: %+v", laps[1])
+	}
+
+	if lap, exists := h.Lap(44, 2); !exists || lap.Number != 2 {
+		t.Errorf("expected lap 2, got %+v", lap)
+	}
+	if lap, exists := h.LastLap(44); !exists || lap.Number != 2 {
+		t.Errorf("expected lap 2 to be the last lap, got %+v", lap)
+	}
+	if _, exists := h.Lap(1, 1); exists {
+		t.Error("expected no laps for an unknown driver")
+	}
+	if drivers := h.Drivers(); len(drivers) != 1 || drivers[0] != 44 {
+		t.Errorf("unexpected drivers: %v", drivers)
+	}
+
+	h.Clear()
+	if len(h.All()) != 0 {
+		t.Error("expected no history after clearing")
+	}
+}
+
+func TestPitAndNeutralisedLaps(t *testing.T) {
+	h := New()
+	green := Messages.Event{TrackStatus: Messages.GreenFlag}
+	safetyCar := Messages.Event{TrackStatus: Messages.YellowFlag, SafetyCar: Messages.SafetyCar}
+
+	h.Add(timing(1, 91*time.Second, Messages.OnTrack), green)
+	h.Add(timing(1, 91*time.Second, Messages.OnTrack), safetyCar)
+	h.Add(timing(2, 110*time.Second, Messages.Pitlane), safetyCar)
+	h.Add(timing(2, 110*time.Second, Messages.PitOut), green)
+	h.Add(timing(3, 95*time.Second, Messages.OnTrack), green)
+	h.Add(timing(4, 90*time.Second, Messages.OnTrack), green)
+
+	laps := h.Laps(44)
+	if len(laps) != 4 {
+		t.Fatalf("expected 4 laps, got %v", laps)
+	}
+
+	if !laps[1].PitIn || laps[1].PitOut || !laps[1].SafetyCar || !laps[1].Yellow {
+		t.Errorf("expected lap 2 to be a pit in lap under the safety car: %+v", laps[1])
+	}
+	// The safety car was still out when lap 3 started
+	if !laps[2].PitOut || laps[2].PitIn || !laps[2].SafetyCar {
+		t.Errorf("expected lap 3 to be an out lap started under the safety car: %+v", laps[2])
+	}
+	if laps[3].PitOut || laps[3].PitIn || laps[3].Yellow || laps[3].SafetyCar {
+		t.Errorf("expected lap 4 to be a normal lap: %+v", laps[3])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	h := New()
+	h.Add(timing(1, 91500*time.Millisecond, Messages.OnTrack), Messages.Event{})
+
+	var out bytes.Buffer
+	if err := h.WriteCSV(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "44,1,") || !strings.Contains(lines[1], ",91.500,30.000,40.000,20.000,Medium,") {
+		t.Errorf("unexpected CSV: %q", out.String())
+	}
+}
+
+func TestStints(t *testing.T) {
+	laps := []Lap{
+		{Number: 1, Tire: Messages.Medium, LapsOnTire: 4},
+		{Number: 2, Tire: Messages.Medium, LapsOnTire: 5},
+		{Number: 3, Tire: Messages.Medium, LapsOnTire: 6, PitIn: true},
+		{Number: 4, Tire: Messages.Hard, LapsOnTire: 1, PitOut: true},
+		{Number: 5, Tire: Messages.Hard, LapsOnTire: 2},
+		// Same compound but new tires
+		{Number: 6, Tire: Messages.Hard, LapsOnTire: 1},
+	}
+
+	stints := Stints(laps)
+	if len(stints) != 3 {
+		t.Fatalf("expected 3 stints, got %v", stints)
+	}
+	if stints[0].Tire != Messages.Medium || stints[0].Laps() != 3 || stints[0].StartAge != 3 {
+		t.Errorf("unexpected first stint: %+v", stints[0])
+	}
+	if stints[1].StartLap != 4 || stints[1].EndLap != 5 || stints[1].StartAge != 0 {
+		t.Errorf("unexpected second stint: %+v", stints[1])
+	}
+	if stints[2].StartLap != 6 || stints[2].Laps() != 1 {
+		t.Errorf("unexpected third stint: %+v", stints[2])
+	}
+}
+
+func TestPendingStop(t *testing.T) {
+	tests := []struct {
+		name     string
+		tires    []Messages.TireType
+		expected bool
+	}{
+		{"one compound", []Messages.TireType{Messages.Medium}, true},
+		{"same compound twice", []Messages.TireType{Messages.Hard, Messages.Hard}, true},
+		{"two compounds", []Messages.TireType{Messages.Medium, Messages.Hard}, false},
+		{"wet race", []Messages.TireType{Messages.Intermediate, Messages.Intermediate}, false},
+		{"no tires", nil, false},
+	}
+
+	for _, test := range tests {
+		var stints []Stint
+		for _, tire := range test.tires {
+			stints = append(stints, Stint{Tire: tire})
+		}
+		if PendingStop(stints) != test.expected {
+			t.Errorf("%s: expected pending stop to be %v", test.name, test.expected)
+		}
+	}
+}
+
+func TestPace(t *testing.T) {
+	var laps []Lap
+	for number := 2; number <= 9; number++ {
+		laps = append(laps, Lap{
+			Number:                  number,
+			Time:                    90*time.Second + time.Duration(number)*100*time.Millisecond,
+			Tire:                    Messages.Medium,
+			LapsOnTire:              number,
+			Position:                2,
+			TimeDiffToPositionAhead: 2 * time.Second,
+		})
+	}
+	laps[2].SafetyCar = true
+	laps[3].TimeDiffToPositionAhead = 500 * time.Millisecond
+	laps[4].Time += 5 * time.Second
+	laps[4].Yellow = true
+	laps[7].PitIn = true
+	laps = append(laps, Lap{Number: 10, Time: 110 * time.Second, Tire: Messages.Hard, LapsOnTire: 1, PitOut: true, Position: 2})
+
+	paces := Pace(laps)
+	if len(paces) != 2 {
+		t.Fatalf("expected 2 stints, got %v", paces)
+	}
+
+	// Laps 2, 3, 7 and 8 are clean and lose a tenth a lap
+	medium := paces[0]
+	if medium.CleanLaps != 4 || !medium.HasDegradation || medium.Degradation != 100*time.Millisecond ||
+		medium.Average != 90500*time.Millisecond {
+		t.Errorf("unexpected pace for the first stint: %+v", medium)
+	}
+	if paces[1].CleanLaps != 0 || paces[1].HasDegradation {
+		t.Errorf("expected no pace for the out lap, got %+v", paces[1])
+	}
+
+	// The last three clean laps are 8, 7 and 3
+	if recent, exists := RecentPace(laps, 3); !exists || recent != 90600*time.Millisecond {
+		t.Errorf("unexpected recent pace: %v", recent)
+	}
+	if _, exists := RecentPace(laps[7:], 3); exists {
+		t.Error("expected no recent pace without clean laps")
+	}
+
+	compounds := CompoundPaces(map[int][]StintPace{44: paces, 1: paces[:1]})
+	if len(compounds) != 1 || compounds[0].Drivers != 2 || compounds[0].CleanLaps != 8 ||
+		compounds[0].Degradation != 100*time.Millisecond {
+		t.Errorf("unexpected compound pace: %+v", compounds)
+	}
+}
+
+func TestImprovement(t *testing.T) {
+	h := New()
+	start := time.Date(2023, 7, 8, 15, 0, 0, 0, time.UTC)
+
+	add := func(number int, lap int, lastLap time.Duration, completed time.Duration) {
+		driver := timing(lap, lastLap, Messages.OnTrack)
+		driver.Number = number
+		driver.Timestamp = start.Add(completed)
+		h.Add(driver, Messages.Event{})
+	}
+
+	add(1, 1, 91*time.Second, 0)
+	add(1, 2, 90*time.Second, 5*time.Minute)
+	add(44, 1, 92*time.Second, 0)
+	add(44, 2, 92500*time.Millisecond, 5*time.Minute)
+	add(44, 3, 91*time.Second, 10*time.Minute)
+
+	if _, improved := h.Improvement(start.Add(11 * time.Minute)); improved {
+		t.Error("expected no improvement after the last lap")
+	}
+	if improvement, _ := h.Improvement(start.Add(time.Minute)); improvement != time.Second {
+		t.Errorf("expected an average improvement of a second, got %v", improvement)
+	}
+}
+
+func TestBattles(t *testing.T) {
+	h := New()
+	green := Messages.Event{TrackStatus: Messages.GreenFlag}
+
+	add := func(number int, lap int, position int, interval time.Duration) {
+		driver := timing(lap, 90*time.Second, Messages.OnTrack)
+		driver.Number = number
+		driver.Position = position
+		driver.TimeDiffToPositionAhead = interval
+		h.Add(driver, green)
+	}
+
+	// 44 closes on 1 for four laps after passing 16 who then drops back
+	for lap := 1; lap <= 5; lap++ {
+		add(1, lap, 1, 0)
+		if lap == 1 {
+			add(16, lap, 2, 500*time.Millisecond)
+			add(44, lap, 3, 300*time.Millisecond)
+			continue
+		}
+		add(44, lap, 2, time.Duration(1000-(lap-2)*200)*time.Millisecond)
+		add(16, lap, 3, time.Duration(lap)*time.Second)
+	}
+
+	battles := h.Battles(time.Second, 3)
+	if len(battles) != 1 {
+		t.Fatalf("expected one battle, got %+v", battles)
+	}
+	battle := battles[0]
+	if battle.Driver != 44 || battle.Ahead != 1 || battle.Laps != 4 || battle.Gap != 400*time.Millisecond ||
+		battle.Trend() != -200*time.Millisecond {
+		t.Errorf("unexpected battle: %+v", battle)
+	}
+
+	if battles := h.Battles(300*time.Millisecond, 1); len(battles) != 0 {
+		t.Errorf("expected no battles within 0.3s, got %+v", battles)
+	}
+}
+
+func TestPositions(t *testing.T) {
+	p := NewPositions()
+
+	add := func(number int, lap int, position int, location Messages.CarLocation) {
+		driver := timing(lap, 0, location)
+		driver.Number = number
+		driver.Position = position
+		p.Add(driver)
+	}
+
+	add(1, 0, 1, Messages.OnTrack)
+	add(44, 0, 2, Messages.OnTrack)
+	add(16, 0, 3, Messages.OnTrack)
+
+	// 44 passes 1 on track, then 1 pits and drops behind 16
+	add(44, 3, 1, Messages.OnTrack)
+	add(1, 3, 2, Messages.OnTrack)
+	add(1, 5, 2, Messages.Pitlane)
+	add(1, 5, 3, Messages.Pitlane)
+	add(16, 5, 2, Messages.OnTrack)
+
+	changes := p.Changes()
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes, got %+v", changes)
+	}
+	if pass := changes[0]; pass.Driver != 44 || pass.Other != 1 || !pass.Gained() || pass.Reason != OnTrack {
+		t.Errorf("unexpected overtake: %+v", pass)
+	}
+	if lost := changes[2]; lost.Driver != 1 || lost.Other != 16 || lost.Gained() || lost.Reason != PitStop {
+		t.Errorf("unexpected pit stop loss: %+v", lost)
+	}
+	if gained := changes[3]; gained.Driver != 16 || gained.Other != 1 || gained.Reason != PitStop {
+		t.Errorf("unexpected pit stop gain: %+v", gained)
+	}
+
+	if grid, exists := p.Grid(16); !exists || grid != 3 {
+		t.Errorf("expected 16 to start third, got %d", grid)
+	}
+
+	// Drivers first seen after the start have no grid slot
+	add(4, 6, 4, Messages.OnTrack)
+	if _, exists := p.Grid(4); exists {
+		t.Error("expected no grid slot for a driver first seen after the start")
+	}
+}
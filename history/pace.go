@@ -0,0 +1,229 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sort"
+	"time"
+)
+
+// TrafficGap is how close a car has to be to the car in front at the end of a lap for the lap to be in traffic
+const TrafficGap = time.Second
+
+// Fewest clean laps needed to work out the degradation for a stint
+const minDegradationLaps = 3
+
+// StintPace is the pace for a stint using only the clean laps
+type StintPace struct {
+	Stint
+	CleanLaps int
+	Average   time.Duration
+	// Change in lap time for each lap of tire age, positive when the tires are getting slower. Only valid when
+	// HasDegradation is true.
+	Degradation    time.Duration
+	HasDegradation bool
+}
+
+// CompoundPace is the pace on a compound combined from every stint on it
+type CompoundPace struct {
+	Tire           Messages.TireType
+	Drivers        int
+	CleanLaps      int
+	Average        time.Duration
+	Degradation    time.Duration
+	HasDegradation bool
+}
+
+// IsCleanLap is false for laps that don't show the pace of the car: the first lap, laps into or out of the pits,
+// laps under a safety car or yellow flag and laps spent close behind another car
+func IsCleanLap(lap Lap) bool {
+	if lap.Number <= 1 || lap.Time == 0 || lap.PitIn || lap.PitOut || lap.SafetyCar || lap.Yellow {
+		return false
+	}
+	return lap.Position == 1 || lap.TimeDiffToPositionAhead == 0 || lap.TimeDiffToPositionAhead >= TrafficGap
+}
+
+// Pace returns the pace of each stint in the laps, stints without any clean laps are included with no pace
+func Pace(laps []Lap) []StintPace {
+	var result []StintPace
+
+	for _, stint := range Stints(laps) {
+		pace := StintPace{Stint: stint}
+
+		var ages []float64
+		var times []float64
+		var total time.Duration
+		for _, lap := range laps {
+			if lap.Number < stint.StartLap || lap.Number > stint.EndLap || !IsCleanLap(lap) {
+				continue
+			}
+
+			pace.CleanLaps++
+			total += lap.Time
+			ages = append(ages, float64(lap.LapsOnTire))
+			times = append(times, float64(lap.Time))
+		}
+
+		if pace.CleanLaps > 0 {
+			pace.Average = total / time.Duration(pace.CleanLaps)
+		}
+		if pace.CleanLaps >= minDegradationLaps {
+			pace.Degradation, pace.HasDegradation = slope(ages, times)
+		}
+
+		result = append(result, pace)
+	}
+
+	return result
+}
+
+// slope is the least squares fit of y against x, false if all the x values are the same
+func slope(x []float64, y []float64) (time.Duration, bool) {
+	n := float64(len(x))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range x {
+		sumX += x[i]
+		sumY += y[i]
+		sumXY += x[i] * y[i]
+		sumXX += x[i] * x[i]
+	}
+
+	divisor := n*sumXX - sumX*sumX
+	if divisor == 0 {
+		return 0, false
+	}
+	return time.Duration((n*sumXY - sumX*sumY) / divisor), true
+}
+
+// RecentPace is the average of the last count clean laps, false if there aren't any
+func RecentPace(laps []Lap, count int) (time.Duration, bool) {
+	var total time.Duration
+	clean := 0
+	for x := len(laps) - 1; x >= 0 && clean < count; x-- {
+		if IsCleanLap(laps[x]) {
+			total += laps[x].Time
+			clean++
+		}
+	}
+
+	if clean == 0 {
+		return 0, false
+	}
+	return total / time.Duration(clean), true
+}
+
+// RecentPaces returns the average of the last count clean laps for each driver who has any
+func (h *History) RecentPaces(count int) map[int]time.Duration {
+	result := make(map[int]time.Duration)
+	for driverNumber, laps := range h.All() {
+		if pace, exists := RecentPace(laps, count); exists {
+			result[driverNumber] = pace
+		}
+	}
+	return result
+}
+
+// Paces returns the pace of every stint for each driver
+func (h *History) Paces() map[int][]StintPace {
+	result := make(map[int][]StintPace)
+	for driverNumber, laps := range h.All() {
+		result[driverNumber] = Pace(laps)
+	}
+	return result
+}
+
+// CompoundPaces combines the stints on each compound, weighting each stint by the number of clean laps in it
+func CompoundPaces(paces map[int][]StintPace) []CompoundPace {
+	type totals struct {
+		drivers         map[int]bool
+		cleanLaps       int
+		total           time.Duration
+		degradation     float64
+		degradationLaps int
+	}
+
+	compounds := make(map[Messages.TireType]*totals)
+	for driverNumber, stints := range paces {
+		for _, stint := range stints {
+			if stint.CleanLaps == 0 {
+				continue
+			}
+
+			compound, exists := compounds[stint.Tire]
+			if !exists {
+				compound = &totals{drivers: make(map[int]bool)}
+				compounds[stint.Tire] = compound
+			}
+
+			compound.drivers[driverNumber] = true
+			compound.cleanLaps += stint.CleanLaps
+			compound.total += stint.Average * time.Duration(stint.CleanLaps)
+			if stint.HasDegradation {
+				compound.degradation += float64(stint.Degradation) * float64(stint.CleanLaps)
+				compound.degradationLaps += stint.CleanLaps
+			}
+		}
+	}
+
+	result := make([]CompoundPace, 0, len(compounds))
+	for tire, compound := range compounds {
+		pace := CompoundPace{
+			Tire:      tire,
+			Drivers:   len(compound.drivers),
+			CleanLaps: compound.cleanLaps,
+			Average:   compound.total / time.Duration(compound.cleanLaps),
+		}
+		if compound.degradationLaps > 0 {
+			pace.Degradation = time.Duration(compound.degradation / float64(compound.degradationLaps))
+			pace.HasDegradation = true
+		}
+		result = append(result, pace)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Tire < result[j].Tire
+	})
+	return result
+}
+
+// Improvement is the average amount drivers improved their best lap by for the laps completed since the given
+// time, false if nobody improved. During qualifying it shows how much faster the track is getting.
+func (h *History) Improvement(since time.Time) (time.Duration, bool) {
+	var total time.Duration
+	count := 0
+	for _, laps := range h.All() {
+		var best time.Duration
+		for _, lap := range laps {
+			if lap.Time == 0 {
+				continue
+			}
+
+			if best > 0 && lap.Time < best && !lap.Completed.Before(since) {
+				total += best - lap.Time
+				count++
+			}
+			if best == 0 || lap.Time < best {
+				best = lap.Time
+			}
+		}
+	}
+
+	if count == 0 {
+		return 0, false
+	}
+	return total / time.Duration(count), true
+}
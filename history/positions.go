@@ -0,0 +1,182 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sync"
+	"time"
+)
+
+type ChangeReason int
+
+const (
+	// Passed or was passed by another car on track
+	OnTrack ChangeReason = iota
+	// The driver or the car they swapped places with was in the pits
+	PitStop
+	// The driver or the car they swapped places with stopped
+	Retirement
+)
+
+func (c ChangeReason) String() string {
+	switch c {
+	case OnTrack:
+		return "On Track"
+	case PitStop:
+		return "Pit Stop"
+	case Retirement:
+		return "Retirement"
+	default:
+		panic("Unhandled change reason")
+	}
+}
+
+// PositionChange is a driver moving from one position to another
+type PositionChange struct {
+	Timestamp time.Time
+	// The lap the driver was on when the position changed
+	Lap    int
+	Driver int
+	From   int
+	To     int
+	// The driver who was in the new position before the change, zero if nobody was
+	Other  int
+	Reason ChangeReason
+}
+
+// Gained is true if the driver moved up
+func (p PositionChange) Gained() bool {
+	return p.To < p.From
+}
+
+// Positions records every change in position during a race and the starting grid. It is safe to use from multiple
+// goroutines.
+type Positions struct {
+	lock    sync.RWMutex
+	latest  map[int]Messages.Timing
+	grid    map[int]int
+	changes []PositionChange
+}
+
+func NewPositions() *Positions {
+	return &Positions{
+		latest: make(map[int]Messages.Timing),
+		grid:   make(map[int]int),
+	}
+}
+
+// Clear removes all the recorded changes and the grid
+func (p *Positions) Clear() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.latest = make(map[int]Messages.Timing)
+	p.grid = make(map[int]int)
+	p.changes = nil
+}
+
+func isStopped(location Messages.CarLocation) bool {
+	return location == Messages.Stopped || location == Messages.OutOfRace
+}
+
+// Add compares the latest timing for a driver with their previous position and records any change. The first
+// position seen for a driver is their grid slot if the race hasn't got past the first lap.
+func (p *Positions) Add(driver Messages.Timing) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	previous, exists := p.latest[driver.Number]
+	p.latest[driver.Number] = driver
+
+	if driver.Position <= 0 {
+		return
+	}
+
+	if !exists || previous.Position <= 0 {
+		if _, hasGrid := p.grid[driver.Number]; !hasGrid && driver.Lap <= 1 {
+			p.grid[driver.Number] = driver.Position
+		}
+		return
+	}
+
+	if previous.Position == driver.Position {
+		return
+	}
+
+	change := PositionChange{
+		Timestamp: driver.Timestamp,
+		Lap:       driver.Lap,
+		Driver:    driver.Number,
+		From:      previous.Position,
+		To:        driver.Position,
+		Reason:    OnTrack,
+	}
+
+	// If the other car's update arrived first the swap has already been recorded from their side
+	if mirror, exists := p.mirror(change); exists {
+		change.Other = mirror.Driver
+		change.Reason = mirror.Reason
+		p.changes = append(p.changes, change)
+		return
+	}
+
+	// Otherwise the other car is still in the position the driver has moved to
+	var other Messages.Timing
+	for number, timing := range p.latest {
+		if number != driver.Number && timing.Position == driver.Position && (change.Other == 0 || number < change.Other) {
+			change.Other = number
+			other = timing
+		}
+	}
+
+	switch {
+	case isInPit(driver.Location) || (change.Other != 0 && isInPit(other.Location)):
+		change.Reason = PitStop
+	case isStopped(driver.Location) || (change.Other != 0 && isStopped(other.Location)):
+		change.Reason = Retirement
+	}
+
+	p.changes = append(p.changes, change)
+}
+
+// mirror finds the recent change for the other side of a swap of positions
+func (p *Positions) mirror(change PositionChange) (PositionChange, bool) {
+	for x := len(p.changes) - 1; x >= 0 && x >= len(p.changes)-len(p.latest); x-- {
+		other := p.changes[x]
+		if other.Other == change.Driver && other.From == change.To && other.To == change.From {
+			return other, true
+		}
+	}
+	return PositionChange{}, false
+}
+
+// Changes returns every position change in the order they happened
+func (p *Positions) Changes() []PositionChange {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	return append([]PositionChange{}, p.changes...)
+}
+
+// Grid returns the starting position of a driver, false if the race wasn't watched from the start
+func (p *Positions) Grid(driver int) (int, bool) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	position, exists := p.grid[driver]
+	return position, exists
+}
@@ -0,0 +1,108 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package history
+
+import "github.com/f1gopher/f1gopherlib/Messages"
+
+// Stint is a run of consecutive laps on one set of tires
+type Stint struct {
+	Tire     Messages.TireType
+	StartLap int
+	EndLap   int
+	// Age of the tires when the stint started, used tires have already done some laps
+	StartAge int
+}
+
+func (s Stint) Laps() int {
+	return s.EndLap - s.StartLap + 1
+}
+
+// Stints splits completed laps into stints, a new stint starts on an out lap or when the tire changes
+func Stints(laps []Lap) []Stint {
+	var result []Stint
+
+	for x, lap := range laps {
+		if x == 0 || lap.PitOut || lap.Tire != laps[x-1].Tire || lap.LapsOnTire < laps[x-1].LapsOnTire {
+			result = append(result, Stint{
+				Tire:     lap.Tire,
+				StartLap: lap.Number,
+				EndLap:   lap.Number,
+				StartAge: max(0, lap.LapsOnTire-1),
+			})
+			continue
+		}
+
+		result[len(result)-1].EndLap = lap.Number
+	}
+
+	return result
+}
+
+// Stints returns the stints for a driver from their completed laps
+func (h *History) Stints(driverNumber int) []Stint {
+	return Stints(h.Laps(driverNumber))
+}
+
+// CurrentStints returns the stints for a driver with the last stint extended to the lap in progress, or a new stint
+// if the driver has changed tires since their last completed lap
+func (h *History) CurrentStints(driver Messages.Timing) []Stint {
+	stints := h.Stints(driver.Number)
+
+	// Nothing is in progress once the driver has stopped or finished
+	if driver.Location == Messages.Stopped || driver.ChequeredFlag || driver.Tire == Messages.Unknown {
+		return stints
+	}
+
+	inProgress := driver.Lap + 1
+	lastLap, exists := h.LastLap(driver.Number)
+	if !exists || len(stints) == 0 ||
+		driver.Tire != lastLap.Tire || driver.LapsOnTire < lastLap.LapsOnTire || driver.Pitstops > lastLap.Pitstops {
+
+		return append(stints, Stint{
+			Tire:     driver.Tire,
+			StartLap: inProgress,
+			EndLap:   inProgress,
+			StartAge: max(0, driver.LapsOnTire-1),
+		})
+	}
+
+	stints[len(stints)-1].EndLap = inProgress
+	return stints
+}
+
+func isDry(tire Messages.TireType) bool {
+	switch tire {
+	case Messages.Soft, Messages.Medium, Messages.Hard, Messages.HYPERSOFT, Messages.ULTRASOFT, Messages.SUPERSOFT:
+		return true
+	default:
+		return false
+	}
+}
+
+// PendingStop is true if the driver still has to make a stop to meet the rule that two different dry compounds are
+// used in a race. The rule doesn't apply once a driver has used intermediate or wet tires.
+func PendingStop(stints []Stint) bool {
+	compounds := make(map[Messages.TireType]bool)
+	for _, stint := range stints {
+		switch {
+		case stint.Tire == Messages.Intermediate || stint.Tire == Messages.Wet:
+			return false
+		case isDry(stint.Tire):
+			compounds[stint.Tire] = true
+		}
+	}
+	return len(compounds) == 1
+}
@@ -16,7 +16,10 @@
 package main
 
 import (
+	"f1gopher/f1gopher-cmdline/cache"
 	"f1gopher/f1gopher-cmdline/menu"
+	"f1gopher/f1gopher-cmdline/sessionUI"
+	"f1gopher/f1gopher-cmdline/webServer"
 	"flag"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
@@ -31,12 +34,26 @@ var Version string
 var BuildTime string
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "download" {
+		download(os.Args[2:])
+		return
+	}
+
 	cachePtr := flag.String("cache", "./.cache", "Path to the folder to cache data in")
+	cacheLimitPtr := flag.String("cachelimit", "", "Largest size of the cache (e.g. 5GB), the least recently downloaded sessions are deleted when it is bigger")
 	logPtr := flag.String("log", "", "Log file")
 	addressPtr := flag.String("address", "", "Web server address")
 	portPtr := flag.String("port", "8000", "Web server port")
+	tokenPtr := flag.String("token", "", "Token required to use the web server playback controls, controls are disabled if not set")
 	delayPtr := flag.Int("delay", 0, "Live delay in seconds")
 	livePtr := flag.Bool("live", false, "Skip menu's and select live feed")
+	speedPtr := flag.String("speed", "1", "Replay playback speed: 0.5, 1, 2, 4, 10 or max")
+	columnsPtr := flag.String("columns", "./columns.json", "Path to the timing tower column layout file")
+	pitLossPtr := flag.String("pitloss", "./pitloss.json", "Path to the file of time lost in the pit lane for each circuit")
+	standingsPtr := flag.String("standings", "./standings.json", "Path to the JSON or CSV file of championship standings before the race")
+	battleGapPtr := flag.Float64("battlegap", 1.0, "Gap in seconds to the car ahead for a driver to be in a battle")
+	battleLapsPtr := flag.Int("battlelaps", 3, "Consecutive laps a driver has to be close to the car ahead to be in a battle")
+	battleAlertsPtr := flag.Bool("battlealerts", false, "Alert when the gap in a battle closes to less than half a second")
 	flag.Parse()
 
 	if len(*logPtr) > 0 {
@@ -57,7 +74,56 @@ func main() {
 		servers = []string{fmt.Sprintf("%s:%s", *addressPtr, *portPtr)}
 	}
 
-	model := menu.NewUI(*cachePtr, servers, time.Duration(*delayPtr)*time.Second, *livePtr, Version)
+	speed, err := sessionUI.ParsePlaybackSpeed(*speedPtr)
+	if err != nil {
+		log.Fatalf("Invalid playback speed: %v", err)
+	}
+
+	var cacheLimit int64
+	if len(*cacheLimitPtr) > 0 {
+		cacheLimit, err = cache.ParseSize(*cacheLimitPtr)
+		if err != nil {
+			log.Fatalf("Invalid cache limit: %v", err)
+		}
+	}
+
+	layouts, err := sessionUI.LoadColumnLayouts(*columnsPtr)
+	if err != nil {
+		log.Fatalf("Error loading column layout: %v", err)
+	}
+
+	pitLosses, err := sessionUI.LoadPitLaneLosses(*pitLossPtr)
+	if err != nil {
+		log.Fatalf("Error loading pit lane losses: %v", err)
+	}
+
+	standings, err := sessionUI.LoadStandings(*standingsPtr)
+	if err != nil {
+		log.Fatalf("Error loading standings: %v", err)
+	}
+
+	battles := sessionUI.NewBattleSettings()
+	battles.Gap = time.Duration(*battleGapPtr * float64(time.Second))
+	battles.Laps = *battleLapsPtr
+	battles.Alerts = *battleAlertsPtr
+	if battles.Gap <= 0 || battles.Laps < 1 {
+		log.Fatalf("Invalid battle settings, the gap and laps must be greater than zero")
+	}
+
+	web := webServer.New(servers, *tokenPtr)
+	webErrors := web.Start()
+	defer web.Shutdown()
+
+	settings := sessionUI.Settings{
+		Layouts:   layouts,
+		PitLosses: pitLosses,
+		Battles:   battles,
+		Standings: standings,
+		LiveDelay: time.Duration(*delayPtr) * time.Second,
+		Speed:     speed,
+	}
+
+	model := menu.NewUI(*cachePtr, cacheLimit, web, webErrors, settings, *livePtr, Version)
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithoutCatchPanics())
 	p.Run()
 }
@@ -0,0 +1,181 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package menu
+
+import (
+	"f1gopher/f1gopher-cmdline/cache"
+	"f1gopher/f1gopher-cmdline/ui"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/f1gopher/f1gopherlib"
+	"strings"
+)
+
+// Lines used by the title, total and help around the list of sessions
+const cacheMenuChrome = 7
+
+// cacheMenu lists the downloaded sessions so they can be deleted
+type cacheMenu struct {
+	cursor        int
+	currentWidth  int
+	currentHeight int
+
+	dir    string
+	limit  int64
+	events []f1gopherlib.RaceEvent
+
+	entries []cache.Entry
+	// Waiting for the delete of the selected session to be confirmed
+	confirming bool
+	message    string
+}
+
+func newCacheMenu(dir string, limit int64, events []f1gopherlib.RaceEvent) *cacheMenu {
+	return &cacheMenu{
+		dir:    dir,
+		limit:  limit,
+		events: events,
+	}
+}
+
+// Enter finds what is in the cache each time the page is shown
+func (m *cacheMenu) Enter() {
+	m.confirming = false
+	m.message = ""
+	m.scan()
+}
+
+func (m *cacheMenu) scan() {
+	entries, err := cache.Scan(m.dir, m.events)
+	if err != nil {
+		m.message = fmt.Sprintf("Error reading the cache: %v", err)
+	}
+	m.entries = entries
+	m.cursor = max(0, min(m.cursor, len(m.entries)-1))
+}
+
+func (m *cacheMenu) Resize(msg tea.WindowSizeMsg) {
+	m.currentWidth = msg.Width
+	m.currentHeight = msg.Height
+}
+
+func (m *cacheMenu) Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd) {
+	newUI = ui.CacheMenu
+
+	msgType, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return newUI, nil
+	}
+
+	if m.confirming {
+		m.confirming = false
+		if msgType.String() == "y" && m.cursor < len(m.entries) {
+			entry := m.entries[m.cursor]
+			if err := cache.Remove(m.dir, entry); err != nil {
+				m.message = fmt.Sprintf("Error deleting %s: %v", entryName(entry), err)
+			} else {
+				m.message = fmt.Sprintf("Deleted %s", entryName(entry))
+			}
+			m.scan()
+		} else {
+			m.message = ""
+		}
+		return newUI, nil
+	}
+
+	m.message = ""
+	switch msgType.String() {
+	case "esc":
+		return ui.MainMenu, nil
+
+	case "up":
+		m.cursor = max(0, m.cursor-1)
+
+	case "down":
+		m.cursor = max(0, min(len(m.entries)-1, m.cursor+1))
+
+	case "d", "delete":
+		if m.cursor < len(m.entries) {
+			m.confirming = true
+			m.message = fmt.Sprintf("Delete %s (%s)? y to confirm", entryName(m.entries[m.cursor]),
+				cache.FormatSize(m.entries[m.cursor].Size))
+		}
+
+	case "t":
+		if m.limit <= 0 {
+			m.message = "No size limit set, start with -cachelimit to set one"
+			break
+		}
+		removed, err := cache.Trim(m.dir, m.entries, m.limit)
+		if err != nil {
+			m.message = fmt.Sprintf("Error trimming the cache: %v", err)
+		} else {
+			m.message = fmt.Sprintf("Deleted %d sessions", len(removed))
+		}
+		m.scan()
+	}
+
+	return newUI, nil
+}
+
+func entryName(entry cache.Entry) string {
+	return cache.Name(entry.Event)
+}
+
+func (m *cacheMenu) View() string {
+	title := lipgloss.NewStyle().
+		Underline(true).
+		Foreground(lipgloss.Color("#AF0202")).
+		Render("Cached Sessions")
+
+	total := fmt.Sprintf("%d sessions using %s", len(m.entries), cache.FormatSize(cache.Total(m.entries)))
+	if m.limit > 0 {
+		total += fmt.Sprintf(" of the %s limit", cache.FormatSize(m.limit))
+	}
+	lines := []string{"", titleStyle.Render(title), titleStyle.Render(total), ""}
+
+	// Only show the sessions that fit with the selected one in view
+	visible := len(m.entries)
+	if m.currentHeight > cacheMenuChrome {
+		visible = m.currentHeight - cacheMenuChrome
+	}
+	first := max(0, min(m.cursor-visible/2, len(m.entries)-visible))
+
+	for x := first; x < len(m.entries) && x < first+visible; x++ {
+		entry := m.entries[x]
+		line := fmt.Sprintf("%-60s %10s   Downloaded %s", entryName(entry), cache.FormatSize(entry.Size),
+			entry.Modified.Local().Format("02 Jan 2006 15:04"))
+
+		if x == m.cursor {
+			lines = append(lines, selectedItemStyle.Render("> "+line))
+		} else {
+			lines = append(lines, itemStyle.Render(line))
+		}
+	}
+	if len(m.entries) == 0 {
+		lines = append(lines, itemStyle.Render("Nothing has been downloaded"))
+	}
+
+	lines = append(lines, "")
+	if len(m.message) > 0 {
+		lines = append(lines, itemStyle.Render(m.message))
+	} else {
+		lines = append(lines, helpStyle.Render("up/down: select • d: delete • t: delete the oldest down to the limit • esc: back"))
+	}
+
+	return strings.Join(lines, "\n")
+}
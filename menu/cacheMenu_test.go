@@ -0,0 +1,67 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package menu
+
+import (
+	"f1gopher/f1gopher-cmdline/cache"
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCacheMenu(t *testing.T) {
+	dir := t.TempDir()
+	events := testEvents()
+	for _, event := range events[:2] {
+		path := filepath.Join(cache.Path(dir, event), "TimingData.jsonStream")
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, make([]byte, 1024), 0644)
+	}
+
+	// The replay menu marks the downloaded sessions
+	replays := newReplayMenuFor(events, dir)
+	replays.Enter()
+	replays.Update(tea.KeyMsg{Type: tea.KeyDown})
+	replays.Update(tea.KeyMsg{Type: tea.KeyRight})
+	if lines := visible(replays); len(lines) != 6 || !strings.HasSuffix(lines[2], "cached)") || !strings.HasSuffix(lines[3], "cached)") {
+		t.Errorf("expected the monza sessions to be cached:\n%s", strings.Join(lines, "\n"))
+	}
+
+	m := newCacheMenu(dir, 0, events)
+	m.Enter()
+	if !strings.Contains(m.View(), "2 sessions using 2.0 KB") {
+		t.Errorf("unexpected cache menu:\n%s", m.View())
+	}
+
+	// Deleting needs confirming
+	m.Update(keyMsg("d"))
+	m.Update(keyMsg("n"))
+	if len(m.entries) != 2 {
+		t.Errorf("expected nothing to be deleted, got %d sessions", len(m.entries))
+	}
+	m.Update(keyMsg("d"))
+	m.Update(keyMsg("y"))
+	if len(m.entries) != 1 {
+		t.Errorf("expected a session to be deleted, got %d sessions", len(m.entries))
+	}
+
+	if page, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.MainMenu {
+		t.Errorf("expected the main menu, got %v", page)
+	}
+}
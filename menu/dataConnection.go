@@ -16,6 +16,7 @@
 package menu
 
 import (
+	"f1gopher/f1gopher-cmdline/sessionUI"
 	"github.com/f1gopher/f1gopherlib"
 	"github.com/f1gopher/f1gopherlib/flowControl"
 	"github.com/f1gopher/f1gopherlib/parser"
@@ -30,12 +31,18 @@ func newLiveConnection(cache string) f1gopherlib.F1GopherLib {
 	return data
 }
 
-func newReplayConnection(cache string, event f1gopherlib.RaceEvent) f1gopherlib.F1GopherLib {
+func newReplayConnection(cache string, event f1gopherlib.RaceEvent, speed sessionUI.PlaybackSpeed) f1gopherlib.F1GopherLib {
+	// Other speeds are paced by the session UI on top of real time
+	flow := flowControl.Realtime
+	if speed == sessionUI.MaxSpeed {
+		flow = flowControl.StraightThrough
+	}
+
 	data, _ := f1gopherlib.CreateReplay(
 		parser.EventTime|parser.Timing|parser.Event|parser.RaceControl|parser.TeamRadio|parser.Weather,
 		event,
 		cache,
-		flowControl.Realtime)
+		flow)
 
 	return data
 }
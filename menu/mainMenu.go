@@ -43,24 +43,32 @@ type mainMenu struct {
 	currentWidth  int
 	currentHeight int
 
-	message     string
-	servers     string
-	nextSession string
-	version     string
+	message      string
+	servers      string
+	serverErrors []string
+	nextSession  string
+	version      string
 }
 
-func newMainMenu(servers []string, version string) *mainMenu {
+func newMainMenu(servers []string, serverErrors []error, version string) *mainMenu {
 
 	menu := []string{
 		"Live",
 		"Replay",
+		"Cache",
 		"Quit"}
 
+	var errs []string
+	for _, err := range serverErrors {
+		errs = append(errs, err.Error())
+	}
+
 	return &mainMenu{
-		cursor:  0,
-		choices: menu,
-		servers: strings.Join(servers, ","),
-		version: version,
+		cursor:       0,
+		choices:      menu,
+		servers:      strings.Join(servers, ","),
+		serverErrors: errs,
+		version:      version,
 	}
 }
 
@@ -101,6 +109,9 @@ func (m *mainMenu) Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd) {
 			case "Replay":
 				newUI = ui.ReplayMenu
 
+			case "Cache":
+				newUI = ui.CacheMenu
+
 			case "Quit":
 				newUI = ui.Quit
 			}
@@ -134,7 +145,7 @@ func (m *mainMenu) View() string {
 
 	var menu string
 	if len(m.message) > 0 {
-		menu = lipgloss.Place(m.currentWidth, m.currentHeight-11,
+		menu = lipgloss.Place(m.currentWidth, m.currentHeight-11-len(m.serverErrors),
 			lipgloss.Center, lipgloss.Center,
 			dialogBoxStyle.Render(s),
 			lipgloss.WithWhitespaceForeground(subtle),
@@ -142,7 +153,7 @@ func (m *mainMenu) View() string {
 
 		menu += "\n\n\n\n" + lipgloss.NewStyle().Width(m.currentWidth).Align(lipgloss.Center).Render(m.message)
 	} else {
-		menu = lipgloss.Place(m.currentWidth, m.currentHeight-2,
+		menu = lipgloss.Place(m.currentWidth, m.currentHeight-2-len(m.serverErrors),
 			lipgloss.Center, lipgloss.Center,
 			dialogBoxStyle.Render(s),
 			lipgloss.WithWhitespaceForeground(subtle),
@@ -153,5 +164,10 @@ func (m *mainMenu) View() string {
 		fmt.Sprintf("Server(s): %s", m.servers))
 	version := lipgloss.NewStyle().Width(m.currentWidth).Align(lipgloss.Right).Render("v" + m.version)
 
+	for _, err := range m.serverErrors {
+		serverInfo = lipgloss.NewStyle().Width(m.currentWidth).Align(lipgloss.Right).Foreground(lipgloss.Color("#FF0000")).Render(
+			fmt.Sprintf("Web server error: %s", err)) + "\n" + serverInfo
+	}
+
 	return fmt.Sprintf("%s\n%s\n%s", menu, serverInfo, version)
 }
@@ -16,16 +16,27 @@
 package menu
 
 import (
+	"f1gopher/f1gopher-cmdline/cache"
 	"f1gopher/f1gopher-cmdline/ui"
 	"fmt"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/f1gopher/f1gopherlib"
 	"github.com/f1gopher/f1gopherlib/Messages"
 	"io"
+	"sort"
+	"strings"
+	"time"
 )
 
+const replayMenuTitle = "Select a session to replay"
+
+// How long before and after the race a weekend counts as this weekend
+const weekendStart = 4 * 24 * time.Hour
+const weekendEnd = 24 * time.Hour
+
 type replayMenu struct {
 	cursor        int
 	currentWidth  int
@@ -33,6 +44,18 @@ type replayMenu struct {
 
 	list   list.Model
 	choice item
+
+	seasons  []*season
+	cacheDir string
+	// Folders of the sessions that have been downloaded
+	cached map[string]bool
+	// Seasons and weekends that have been opened, by key
+	expanded map[string]bool
+	// Text to match sessions against and whether it is being typed
+	filter    string
+	filtering bool
+	message   string
+	now       func() time.Time
 }
 
 var (
@@ -44,11 +67,104 @@ var (
 	quitTextStyle     = lipgloss.NewStyle().Margin(1, 0, 2, 4)
 )
 
+// weekend is the sessions for one race weekend in the order they happened
+type weekend struct {
+	name     string
+	country  string
+	track    string
+	raceTime time.Time
+	sessions []f1gopherlib.RaceEvent
+}
+
+func (w *weekend) key() string {
+	return w.raceTime.Format("2006-01-02") + " " + w.name
+}
+
+// season is the race weekends for a year, most recent first
+type season struct {
+	year     int
+	weekends []*weekend
+}
+
+type itemKind int
+
+const (
+	seasonItem itemKind = iota
+	weekendItem
+	sessionItem
+)
+
 type item struct {
-	event f1gopherlib.RaceEvent
+	kind     itemKind
+	season   *season
+	weekend  *weekend
+	event    f1gopherlib.RaceEvent
+	expanded bool
+	// Shown in the filter results so it needs the full name of the session
+	filtered bool
+	// The data for the session has been downloaded
+	cached bool
+}
+
+func (i item) key() string {
+	switch i.kind {
+	case seasonItem:
+		return fmt.Sprintf("%d", i.season.year)
+	case weekendItem:
+		return i.weekend.key()
+	case sessionItem:
+		return i.weekend.key() + " " + i.event.EventTime.Format(time.RFC3339)
+	default:
+		panic("Unhandled replay menu item")
+	}
 }
 
-func (i item) FilterValue() string { return "" }
+// FilterValue is the text searched by the filter, only sessions are matched
+func (i item) FilterValue() string {
+	if i.kind != sessionItem {
+		return ""
+	}
+	return strings.ToLower(fmt.Sprintf("%d %s %s %s %s", i.event.RaceTime.Year(), i.event.Country,
+		i.event.TrackName, i.event.Name, i.event.Type.String()))
+}
+
+func (i item) String() string {
+	arrow := "▸"
+	if i.expanded {
+		arrow = "▾"
+	}
+
+	switch i.kind {
+	case seasonItem:
+		if i.expanded {
+			return fmt.Sprintf("%s %d", arrow, i.season.year)
+		}
+		return fmt.Sprintf("%s %d (%d race weekends)", arrow, i.season.year, len(i.season.weekends))
+
+	case weekendItem:
+		return fmt.Sprintf("    %s %s - %s, %s (%s)", arrow, i.weekend.name, i.weekend.track, i.weekend.country,
+			i.weekend.raceTime.Format("2 Jan"))
+
+	case sessionItem:
+		timezone := i.event.Timezone()
+		if timezone == nil {
+			timezone = time.UTC
+		}
+		start := i.event.EventTime.In(timezone).Format("Mon 2 Jan 15:04")
+		if i.cached {
+			start += ", cached"
+		}
+
+		if i.filtered {
+			return fmt.Sprintf("%d %s - %s - %s (%s)", i.event.RaceTime.Year(), i.event.Country, i.event.Name,
+				i.event.Type.String(), start)
+		}
+		return fmt.Sprintf("          %s (%s)", i.event.Type.String(), start)
+
+	default:
+		panic("Unhandled replay menu item")
+	}
+}
 
 type itemDelegate struct{}
 
@@ -61,7 +177,7 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 		return
 	}
 
-	str := fmt.Sprintf("%d. %d %s %s", index+1, i.event.RaceTime.Year(), i.event.Country, i.event.Type.String())
+	str := i.String()
 
 	fn := itemStyle.Render
 	if index == m.Index() {
@@ -73,19 +189,84 @@ func (d itemDelegate) Render(w io.Writer, m list.Model, index int, listItem list
 	fmt.Fprint(w, fn(str))
 }
 
-func newReplayMenu() *replayMenu {
-	var items []list.Item
+// groupEvents puts the sessions into race weekends and seasons, most recent first
+func groupEvents(events []f1gopherlib.RaceEvent) []*season {
+	var seasons []*season
+	years := make(map[int]*season)
+	weekends := make(map[string]*weekend)
 
-	for _, event := range f1gopherlib.RaceHistory() {
+	for _, event := range events {
 		if event.Type == Messages.PreSeasonSession {
 			continue
 		}
 
-		items = append(items, item{event: event})
+		current := &weekend{name: event.Name, country: event.Country, track: event.TrackName, raceTime: event.RaceTime}
+		existing, exists := weekends[current.key()]
+		if !exists {
+			year, exists := years[event.RaceTime.Year()]
+			if !exists {
+				year = &season{year: event.RaceTime.Year()}
+				years[year.year] = year
+				seasons = append(seasons, year)
+			}
+
+			existing = current
+			weekends[current.key()] = existing
+			year.weekends = append(year.weekends, existing)
+		}
+		existing.sessions = append(existing.sessions, event)
+	}
+
+	sort.Slice(seasons, func(i, j int) bool {
+		return seasons[i].year > seasons[j].year
+	})
+	for _, year := range seasons {
+		sort.SliceStable(year.weekends, func(i, j int) bool {
+			return year.weekends[i].raceTime.After(year.weekends[j].raceTime)
+		})
+		for _, w := range year.weekends {
+			sort.SliceStable(w.sessions, func(i, j int) bool {
+				return w.sessions[i].EventTime.Before(w.sessions[j].EventTime)
+			})
+		}
+	}
+
+	return seasons
+}
+
+// matchesFilter is true if every word in the filter fuzzy matches one of the words for the session. Matching
+// within a word stops a year like 2023 matching the digits spread across 2024 and Practice 3.
+func matchesFilter(filter string, target string) bool {
+	words := strings.Fields(target)
+	for _, word := range strings.Fields(strings.ToLower(filter)) {
+		if len(list.DefaultFilter(word, words)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// FindEvents is the sessions that match a search the same way as the replay menu
+func FindEvents(events []f1gopherlib.RaceEvent, filter string) []f1gopherlib.RaceEvent {
+	var result []f1gopherlib.RaceEvent
+	for _, event := range events {
+		if event.Type == Messages.PreSeasonSession {
+			continue
+		}
+		if matchesFilter(filter, item{kind: sessionItem, event: event}.FilterValue()) {
+			result = append(result, event)
+		}
 	}
+	return result
+}
+
+func newReplayMenu(cacheDir string) *replayMenu {
+	return newReplayMenuFor(f1gopherlib.RaceHistory(), cacheDir)
+}
 
-	l := list.New(items, itemDelegate{}, 200, 20)
-	l.Title = "Select a session to replay"
+func newReplayMenuFor(events []f1gopherlib.RaceEvent, cacheDir string) *replayMenu {
+	l := list.New(nil, itemDelegate{}, 200, 20)
+	l.Title = replayMenuTitle
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.DisableQuitKeybindings()
@@ -93,10 +274,188 @@ func newReplayMenu() *replayMenu {
 	l.Styles.PaginationStyle = paginationStyle
 	l.Styles.HelpStyle = helpStyle
 
-	return &replayMenu{
-		cursor: 0,
-		list:   l,
+	// Left and right open and close the seasons and weekends instead of changing page
+	l.KeyMap.PrevPage = key.NewBinding(key.WithKeys("pgup", "b", "u"), key.WithHelp("pgup", "prev page"))
+	l.KeyMap.NextPage = key.NewBinding(key.WithKeys("pgdown", "f", "d"), key.WithHelp("pgdown", "next page"))
+	l.AdditionalShortHelpKeys = func() []key.Binding {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("right", "left"), key.WithHelp("→/←", "open/close")),
+			key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search")),
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "last race")),
+			key.NewBinding(key.WithKeys("w"), key.WithHelp("w", "this weekend")),
+		}
+	}
+
+	m := &replayMenu{
+		cursor:   0,
+		list:     l,
+		seasons:  groupEvents(events),
+		cacheDir: cacheDir,
+		cached:   make(map[string]bool),
+		expanded: make(map[string]bool),
+		now:      time.Now,
+	}
+
+	// Start with the latest season open
+	if len(m.seasons) > 0 {
+		m.expanded[item{kind: seasonItem, season: m.seasons[0]}.key()] = true
 	}
+	m.refresh("")
+
+	return m
+}
+
+// items is the seasons with any open weekends, or every session matching the filter
+func (m *replayMenu) items() []list.Item {
+	var items []list.Item
+
+	for _, year := range m.seasons {
+		current := item{kind: seasonItem, season: year}
+		current.expanded = m.expanded[current.key()]
+		if len(m.filter) == 0 {
+			items = append(items, current)
+		}
+
+		for _, w := range year.weekends {
+			weekendEntry := item{kind: weekendItem, season: year, weekend: w}
+			weekendEntry.expanded = m.expanded[weekendEntry.key()]
+			if len(m.filter) == 0 {
+				if !current.expanded {
+					continue
+				}
+				items = append(items, weekendEntry)
+			}
+
+			for _, event := range w.sessions {
+				session := item{kind: sessionItem, season: year, weekend: w, event: event, filtered: len(m.filter) > 0,
+					cached: m.cached[cache.Path(m.cacheDir, event)]}
+				if len(m.filter) > 0 && matchesFilter(m.filter, session.FilterValue()) {
+					items = append(items, session)
+				} else if len(m.filter) == 0 && weekendEntry.expanded {
+					items = append(items, session)
+				}
+			}
+		}
+	}
+
+	return items
+}
+
+// refresh rebuilds the list keeping the item with the key selected if it is still shown
+func (m *replayMenu) refresh(selected string) {
+	items := m.items()
+	m.list.SetItems(items)
+
+	m.list.ResetSelected()
+	for x, current := range items {
+		if current.(item).key() == selected {
+			m.list.Select(x)
+			break
+		}
+	}
+
+	m.list.Title = replayMenuTitle
+	if m.filtering || len(m.filter) > 0 {
+		m.list.Title = fmt.Sprintf("Search: %s", m.filter)
+		if m.filtering {
+			m.list.Title += "_"
+		}
+		m.list.Title += fmt.Sprintf(" (%d sessions)", len(items))
+	}
+	if len(m.message) > 0 {
+		m.list.Title += " - " + m.message
+	}
+}
+
+func (m *replayMenu) selected() (item, bool) {
+	selected, ok := m.list.SelectedItem().(item)
+	return selected, ok
+}
+
+func (m *replayMenu) selectedKey() string {
+	if selected, ok := m.selected(); ok {
+		return selected.key()
+	}
+	return ""
+}
+
+// setExpanded opens or closes the selected season or weekend, closing a session closes its weekend
+func (m *replayMenu) setExpanded(expanded bool) {
+	selected, ok := m.selected()
+	if !ok {
+		return
+	}
+
+	if selected.kind == sessionItem {
+		if expanded {
+			return
+		}
+		selected = item{kind: weekendItem, season: selected.season, weekend: selected.weekend}
+	} else if selected.kind == weekendItem && !expanded && !m.expanded[selected.key()] {
+		selected = item{kind: seasonItem, season: selected.season}
+	}
+
+	m.expanded[selected.key()] = expanded
+	m.refresh(selected.key())
+}
+
+// show clears the search and opens the season and weekend for the session so it can be selected
+func (m *replayMenu) show(session item) {
+	m.filter = ""
+	m.filtering = false
+	m.expanded[item{kind: seasonItem, season: session.season}.key()] = true
+	m.expanded[session.weekend.key()] = true
+	m.refresh(session.key())
+}
+
+// lastRace selects the most recent race that can be replayed
+func (m *replayMenu) lastRace() {
+	for _, year := range m.seasons {
+		for _, w := range year.weekends {
+			for _, event := range w.sessions {
+				if event.Type == Messages.RaceSession {
+					m.show(item{kind: sessionItem, season: year, weekend: w, event: event})
+					return
+				}
+			}
+		}
+	}
+
+	m.message = "There are no races to replay"
+	m.refresh(m.selectedKey())
+}
+
+// thisWeekend selects the latest session that can be replayed from a race weekend happening now
+func (m *replayMenu) thisWeekend() {
+	now := m.now()
+	for _, year := range m.seasons {
+		for _, w := range year.weekends {
+			if now.After(w.raceTime.Add(-weekendStart)) && now.Before(w.raceTime.Add(weekendEnd)) {
+				m.show(item{kind: sessionItem, season: year, weekend: w, event: w.sessions[len(w.sessions)-1]})
+				return
+			}
+		}
+	}
+
+	m.message = "There are no sessions to replay from this weekend"
+	m.refresh(m.selectedKey())
+}
+
+// Enter finds which sessions have been downloaded each time the menu is shown
+func (m *replayMenu) Enter() {
+	var events []f1gopherlib.RaceEvent
+	for _, year := range m.seasons {
+		for _, w := range year.weekends {
+			events = append(events, w.sessions...)
+		}
+	}
+
+	m.cached = make(map[string]bool)
+	entries, _ := cache.Scan(m.cacheDir, events)
+	for _, entry := range entries {
+		m.cached[entry.Path] = true
+	}
+	m.refresh(m.selectedKey())
 }
 
 func (m *replayMenu) Resize(msg tea.WindowSizeMsg) {
@@ -110,18 +469,84 @@ func (m *replayMenu) Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd) {
 
 	switch msgType := msg.(type) {
 	case tea.KeyMsg:
+		m.message = ""
+
+		// While typing a search the keys go to the search text
+		if m.filtering {
+			switch msgType.Type {
+			case tea.KeyEsc:
+				m.filter = ""
+				m.filtering = false
+				m.refresh("")
+				return newUI, nil
+
+			case tea.KeyEnter:
+				m.filtering = false
+				m.refresh(m.selectedKey())
+				return newUI, nil
+
+			case tea.KeyBackspace:
+				if len(m.filter) > 0 {
+					runes := []rune(m.filter)
+					m.filter = string(runes[:len(runes)-1])
+				}
+				m.refresh("")
+				return newUI, nil
+
+			case tea.KeyRunes, tea.KeySpace:
+				m.filter += string(msgType.Runes)
+				m.refresh("")
+				return newUI, nil
+			}
+			break
+		}
+
 		switch msgType.Type {
 		case tea.KeyEnter, tea.KeySpace:
-			selected, ok := m.list.SelectedItem().(item)
-			if ok {
-				m.choice = selected
+			selected, ok := m.selected()
+			if !ok {
+				return newUI, nil
+			}
+
+			if selected.kind != sessionItem {
+				m.setExpanded(!selected.expanded)
+				return newUI, nil
 			}
 
+			m.choice = selected
 			return ui.Replay, nil
 
+		case tea.KeyRight:
+			m.setExpanded(true)
+			return newUI, nil
+
+		case tea.KeyLeft:
+			m.setExpanded(false)
+			return newUI, nil
+
 		case tea.KeyEsc:
+			if len(m.filter) > 0 {
+				m.filter = ""
+				m.refresh("")
+				return newUI, nil
+			}
 			return ui.MainMenu, nil
 		}
+
+		switch msgType.String() {
+		case "/":
+			m.filtering = true
+			m.refresh("")
+			return newUI, nil
+
+		case "r":
+			m.lastRace()
+			return newUI, nil
+
+		case "w":
+			m.thisWeekend()
+			return newUI, nil
+		}
 	}
 
 	var cmd tea.Cmd
@@ -0,0 +1,149 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package menu
+
+import (
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testEvents() []f1gopherlib.RaceEvent {
+	silverstone := time.Date(2023, 7, 9, 14, 0, 0, 0, time.UTC)
+	monza := time.Date(2023, 9, 3, 13, 0, 0, 0, time.UTC)
+	abuDhabi := time.Date(2022, 11, 20, 13, 0, 0, 0, time.UTC)
+
+	event := func(country string, track string, name string, race time.Time, session Messages.SessionType, start time.Time) f1gopherlib.RaceEvent {
+		return f1gopherlib.RaceEvent{Country: country, TrackName: track, Name: name, RaceTime: race, EventTime: start, Type: session}
+	}
+
+	// Most recent first like the race history
+	return []f1gopherlib.RaceEvent{
+		event("Italy", "Monza", "Italian Grand Prix", monza, Messages.RaceSession, monza),
+		event("Italy", "Monza", "Italian Grand Prix", monza, Messages.QualifyingSession, monza.Add(-25*time.Hour)),
+		event("Britain", "Silverstone", "British Grand Prix", silverstone, Messages.RaceSession, silverstone),
+		event("Britain", "Silverstone", "British Grand Prix", silverstone, Messages.QualifyingSession, silverstone.Add(-24*time.Hour)),
+		event("Britain", "Silverstone", "British Grand Prix", silverstone, Messages.Practice1Session, silverstone.Add(-48*time.Hour)),
+		event("UAE", "Yas Marina Circuit", "Abu Dhabi Grand Prix", abuDhabi, Messages.RaceSession, abuDhabi),
+		event("Bahrain", "Sakhir", "Pre-Season Testing", abuDhabi, Messages.PreSeasonSession, abuDhabi),
+	}
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func visible(m *replayMenu) []string {
+	var lines []string
+	for _, current := range m.list.Items() {
+		lines = append(lines, strings.TrimSpace(current.(item).String()))
+	}
+	return lines
+}
+
+func TestReplayMenuGrouping(t *testing.T) {
+	m := newReplayMenuFor(testEvents(), t.TempDir())
+
+	// The latest season starts open with its weekends closed
+	expected := []string{
+		"▾ 2023",
+		"▸ Italian Grand Prix - Monza, Italy (3 Sep)",
+		"▸ British Grand Prix - Silverstone, Britain (9 Jul)",
+		"▸ 2022 (1 race weekends)",
+	}
+	if lines := visible(m); strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Fatalf("unexpected menu:\n%s", strings.Join(lines, "\n"))
+	}
+
+	// Open the British Grand Prix, the sessions are in the order they happened
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyRight})
+	lines := visible(m)
+	if len(lines) != 7 || !strings.HasPrefix(lines[3], "Practice 1") || !strings.HasPrefix(lines[5], "Race") {
+		t.Fatalf("unexpected menu:\n%s", strings.Join(lines, "\n"))
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if page, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); page != ui.Replay || m.choice.event.Type != Messages.Practice1Session {
+		t.Errorf("expected to replay practice 1, got %v %v", page, m.choice.event.Type)
+	}
+
+	// Left on a session closes its weekend
+	m.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	if len(visible(m)) != 4 {
+		t.Errorf("expected the weekend to close:\n%s", strings.Join(visible(m), "\n"))
+	}
+}
+
+func TestReplayMenuSearch(t *testing.T) {
+	m := newReplayMenuFor(testEvents(), t.TempDir())
+
+	m.Update(keyMsg("/"))
+	for _, r := range "silv 2023 qual" {
+		m.Update(keyMsg(string(r)))
+	}
+	lines := visible(m)
+	if len(lines) != 1 || !strings.HasPrefix(lines[0], "2023 Britain - British Grand Prix - Qualifying") {
+		t.Fatalf("unexpected search results:\n%s", strings.Join(lines, "\n"))
+	}
+
+	// Backspace removes a whole character, not the last byte of it
+	m.Update(keyMsg("é"))
+	m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if m.filter != "silv 2023 qual" {
+		t.Errorf("unexpected search: %q", m.filter)
+	}
+
+	// Keys typed into the search don't open the session until the search is finished
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if page, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); page != ui.Replay || m.choice.event.Country != "Britain" {
+		t.Errorf("expected to replay the british qualifying, got %v %v", page, m.choice.event.Country)
+	}
+
+	// Escape clears the search before leaving the menu
+	if page, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.ReplayMenu || len(visible(m)) != 4 {
+		t.Errorf("expected the search to be cleared, got %v", page)
+	}
+	if page, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.MainMenu {
+		t.Errorf("expected the main menu, got %v", page)
+	}
+}
+
+func TestReplayMenuShortcuts(t *testing.T) {
+	m := newReplayMenuFor(testEvents(), t.TempDir())
+
+	m.Update(keyMsg("r"))
+	if selected, _ := m.selected(); selected.event.Name != "Italian Grand Prix" || selected.event.Type != Messages.RaceSession {
+		t.Errorf("expected the last race to be selected, got %+v", selected.event)
+	}
+
+	m.now = func() time.Time { return time.Date(2023, 7, 8, 18, 0, 0, 0, time.UTC) }
+	m.Update(keyMsg("w"))
+	if selected, _ := m.selected(); selected.event.Name != "British Grand Prix" || selected.event.Type != Messages.RaceSession {
+		t.Errorf("expected the latest session from this weekend to be selected, got %+v", selected.event)
+	}
+
+	m.now = func() time.Time { return time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC) }
+	m.Update(keyMsg("w"))
+	if !strings.Contains(m.list.Title, "no sessions to replay from this weekend") {
+		t.Errorf("expected a message, got %s", m.list.Title)
+	}
+}
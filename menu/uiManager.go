@@ -16,8 +16,10 @@
 package menu
 
 import (
+	"f1gopher/f1gopher-cmdline/cache"
 	"f1gopher/f1gopher-cmdline/sessionUI"
 	"f1gopher/f1gopher-cmdline/ui"
+	"f1gopher/f1gopher-cmdline/webServer"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/f1gopher/f1gopherlib"
 	"github.com/f1gopher/f1gopherlib/Messages"
@@ -33,23 +35,29 @@ type UIManager struct {
 	currentUI     ui.Page
 	sessionUI     sessionUI.SessionUI
 	replayMenu    *replayMenu
+	cacheMenu     *cacheMenu
 	cache         string
-	liveDelay     time.Duration
-	servers       []string
+	cacheLimit    int64
+	settings      sessionUI.Settings
+	web           *webServer.Server
 	display       string
 }
 
-func NewUI(cache string, servers []string, liveDelay time.Duration, displayLive bool, version string) *UIManager {
+func NewUI(cacheDir string, cacheLimit int64, web *webServer.Server, webErrors []error, settings sessionUI.Settings, displayLive bool, version string) *UIManager {
 	display := &UIManager{
 		err:        nil,
-		menu:       newMainMenu(servers, version),
+		menu:       newMainMenu(web.Addresses(), webErrors, version),
 		currentUI:  ui.MainMenu,
-		replayMenu: newReplayMenu(),
-		cache:      cache,
-		liveDelay:  liveDelay,
-		servers:    servers,
+		replayMenu: newReplayMenu(cacheDir),
+		cacheMenu:  newCacheMenu(cacheDir, cacheLimit, f1gopherlib.RaceHistory()),
+		cache:      cacheDir,
+		cacheLimit: cacheLimit,
+		settings:   settings,
+		web:        web,
 	}
 
+	display.trimCache()
+
 	if displayLive {
 		liveConnection := newLiveConnection(display.cache)
 
@@ -113,18 +121,43 @@ func (m UIManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 				}
 
-			case ui.Live, ui.Replay:
+				switch m.currentUI {
+				case ui.ReplayMenu:
+					m.replayMenu.Enter()
+				case ui.CacheMenu:
+					m.cacheMenu.Enter()
+				}
+
+			case ui.Live, ui.Replay, ui.DriverDetail, ui.Comparison, ui.GapChart, ui.StintTimeline, ui.PaceAnalysis, ui.Standings, ui.Bookmarks:
 				m.currentUI, cmds = m.sessionUI.Update(msgType)
-				if m.currentUI != ui.Live && m.currentUI != ui.Replay {
+				if !isSessionPage(m.currentUI) {
+					m.web.SetSession(nil)
 					m.sessionUI.Leave()
 					m.sessionUI = nil
+					m.trimCache()
+					m.menu.Enter()
+				}
+
+			case ui.CacheMenu:
+				m.currentUI, cmds = m.cacheMenu.Update(msgType)
+				if m.currentUI == ui.MainMenu {
 					m.menu.Enter()
 				}
 
 			case ui.ReplayMenu:
 				m.currentUI, cmds = m.replayMenu.Update(msgType)
 				if m.currentUI == ui.Replay {
-					m.sessionUI = m.createSessionUI(newReplayConnection(m.cache, m.replayMenu.choice.event), false)
+					event := m.replayMenu.choice.event
+					m.sessionUI = m.createSessionUI(newReplayConnection(m.cache, event, m.settings.Speed), false)
+					m.sessionUI.SetReplaySource(func() f1gopherlib.F1GopherLib {
+						return newReplayConnection(m.cache, event, m.settings.Speed)
+					})
+
+					// Without the saved bookmarks new ones would overwrite them so don't allow any
+					bookmarks, err := sessionUI.LoadBookmarks(sessionUI.BookmarkPath(m.cache, event))
+					if err == nil {
+						m.sessionUI.SetBookmarks(bookmarks)
+					}
 				}
 			}
 		}
@@ -137,6 +170,7 @@ func (m UIManager) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.menu.Resize(msgType)
 		m.replayMenu.Resize(msgType)
+		m.cacheMenu.Resize(msgType)
 		if m.sessionUI != nil {
 			m.sessionUI.Resize(msgType)
 		}
@@ -150,12 +184,15 @@ func (m UIManager) View() string {
 	case ui.MainMenu:
 		return m.menu.View()
 
-	case ui.Live, ui.Replay:
+	case ui.Live, ui.Replay, ui.DriverDetail, ui.Comparison, ui.GapChart, ui.StintTimeline, ui.PaceAnalysis, ui.Standings, ui.Bookmarks:
 		return m.sessionUI.View()
 
 	case ui.ReplayMenu:
 		return m.replayMenu.View()
 
+	case ui.CacheMenu:
+		return m.cacheMenu.View()
+
 	case ui.Quit:
 
 	default:
@@ -165,21 +202,44 @@ func (m UIManager) View() string {
 	return ""
 }
 
+// trimCache removes the least recently downloaded sessions once the cache is over the size limit
+func (m UIManager) trimCache() {
+	if m.cacheLimit <= 0 {
+		return
+	}
+
+	entries, err := cache.Scan(m.cache, f1gopherlib.RaceHistory())
+	if err == nil {
+		cache.Trim(m.cache, entries, m.cacheLimit)
+	}
+}
+
+// isSessionPage is true for the pages displayed by the session UI
+func isSessionPage(page ui.Page) bool {
+	switch page {
+	case ui.Live, ui.Replay, ui.DriverDetail, ui.Comparison, ui.GapChart, ui.StintTimeline, ui.PaceAnalysis, ui.Standings, ui.Bookmarks:
+		return true
+	default:
+		return false
+	}
+}
+
 func (m UIManager) createSessionUI(data f1gopherlib.F1GopherLib, isLive bool) sessionUI.SessionUI {
 
 	var result sessionUI.SessionUI
 
 	switch data.Session() {
 	case Messages.Practice1Session, Messages.Practice2Session, Messages.Practice3Session, Messages.QualifyingSession, Messages.PreSeasonSession:
-		result = sessionUI.NewPracticeQualifyingUI(m.servers, m.liveDelay)
+		result = sessionUI.NewPracticeQualifyingUI(m.web, m.settings)
 
 	case Messages.SprintSession, Messages.RaceSession:
-		result = sessionUI.NewRaceUI(m.servers, m.liveDelay)
+		result = sessionUI.NewRaceUI(m.web, m.settings)
 
 	default:
 		panic("Unhandled session type: " + data.Session().String())
 	}
 
 	result.Enter(data, m.currentUI, isLive)
+	m.web.SetSession(result.WebHandler())
 	return result
 }
@@ -19,12 +19,48 @@ import (
 	"f1gopher/f1gopher-cmdline/ui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/f1gopher/f1gopherlib"
+	"net/http"
+	"time"
 )
 
+// WebPublisher receives the HTML version of the display every time it is rendered
+type WebPublisher interface {
+	Publish(html string)
+}
+
+// Settings are the options chosen at launch that every session is displayed with
+type Settings struct {
+	Layouts   *ColumnLayouts
+	PitLosses *PitLaneLosses
+	Battles   BattleSettings
+	Standings *Standings
+	// How long live sessions are held back for
+	LiveDelay time.Duration
+	// Speed replays start at
+	Speed PlaybackSpeed
+}
+
+// NewSettings creates settings with the default layouts and battle detection, no pit lane losses or standings, no
+// live delay and replays at normal speed
+func NewSettings() Settings {
+	return Settings{
+		Layouts:   NewColumnLayouts(""),
+		PitLosses: NewPitLaneLosses(),
+		Battles:   NewBattleSettings(),
+		Standings: NewStandings(),
+		Speed:     NormalSpeed,
+	}
+}
+
 type SessionUI interface {
 	Enter(data f1gopherlib.F1GopherLib, ui ui.Page, isLive bool)
 	Leave()
 	Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd)
 	Resize(msg tea.WindowSizeMsg)
 	View() string
+	WebHandler() http.Handler
+	// SetReplaySource is how to open the replay again from the start so it can seek backwards
+	SetReplaySource(open func() f1gopherlib.F1GopherLib)
+	// SetBookmarks is the bookmarks saved for a replay
+	SetBookmarks(bookmarks *Bookmarks)
 }
@@ -0,0 +1,188 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"time"
+)
+
+// An alert is raised when the gap in a battle closes to less than this
+const battleAlertGap = 500 * time.Millisecond
+
+// How long an alert is displayed for in session time
+const battleAlertDuration = 30 * time.Second
+
+// BattleSettings is how close a driver has to be to the car ahead, and for how many laps, to be in a battle and
+// whether to alert when a battle gets close
+type BattleSettings struct {
+	Gap    time.Duration
+	Laps   int
+	Alerts bool
+}
+
+func NewBattleSettings() BattleSettings {
+	return BattleSettings{
+		Gap:  time.Second,
+		Laps: 3,
+	}
+}
+
+// battleAlert is a battle that has closed to within the alert gap
+type battleAlert struct {
+	timestamp time.Time
+	driver    int
+	ahead     int
+	gap       time.Duration
+}
+
+// battles returns the current battles, only races and sprints have battles
+func (s *sessionBase) battles() []history.Battle {
+	if !s.isRace() {
+		return nil
+	}
+	return s.history.Battles(s.battleSettings.Gap, s.battleSettings.Laps)
+}
+
+// battleGap is the live gap to the car ahead if the driver is still behind the same car, otherwise the gap at the
+// end of their last lap
+func battleGap(battle history.Battle, drivers map[int]Messages.Timing) time.Duration {
+	driver, exists := drivers[battle.Driver]
+	ahead, aheadExists := drivers[battle.Ahead]
+	if exists && aheadExists && driver.Position == ahead.Position+1 && driver.TimeDiffToPositionAhead > 0 {
+		return driver.TimeDiffToPositionAhead
+	}
+	return battle.Gap
+}
+
+// checkBattleAlerts raises an alert for each battle where the gap has closed below the alert gap since the last
+// timing update. Only called by listen as the timing arrives.
+func (s *sessionBase) checkBattleAlerts() {
+	if !s.battleSettings.Alerts {
+		return
+	}
+
+	s.eventLock.Lock()
+	now := s.eventTime
+	s.eventLock.Unlock()
+
+	battles := s.battles()
+
+	s.battleLock.Lock()
+	defer s.battleLock.Unlock()
+
+	// Forget alerts that are no longer displayed
+	current := s.battleAlerts[:0]
+	for _, alert := range s.battleAlerts {
+		if now.Sub(alert.timestamp) <= battleAlertDuration {
+			current = append(current, alert)
+		}
+	}
+	s.battleAlerts = current
+
+	gaps := make(map[int]time.Duration, len(battles))
+	for _, battle := range battles {
+		gap := battleGap(battle, s.data)
+		gaps[battle.Driver] = gap
+
+		previous, exists := s.battleGaps[battle.Driver]
+		if exists && previous >= battleAlertGap && gap < battleAlertGap {
+			s.battleAlerts = append(s.battleAlerts, battleAlert{
+				timestamp: now,
+				driver:    battle.Driver,
+				ahead:     battle.Ahead,
+				gap:       gap,
+			})
+		}
+	}
+	s.battleGaps = gaps
+}
+
+func driversByNumber(drivers []Messages.Timing) map[int]Messages.Timing {
+	result := make(map[int]Messages.Timing, len(drivers))
+	for _, driver := range drivers {
+		result[driver.Number] = driver
+	}
+	return result
+}
+
+// battleLines is the panel listing each battle with how long it has lasted, which way the gap is going and whether
+// the driver behind can use DRS. Empty when there are no battles.
+func (s *sessionBase) battleLines(battles []history.Battle, drivers map[int]Messages.Timing, html bool) []string {
+	if len(battles) == 0 {
+		return nil
+	}
+
+	s.eventLock.Lock()
+	now := s.eventTime
+	drsEnabled := s.event.DRSEnabled
+	s.eventLock.Unlock()
+
+	columns := []layoutColumn{
+		{column: &column{header: "Battle"}, width: 13},
+		{column: &column{header: "Laps"}, width: 6},
+		{column: &column{header: "Gap"}, width: timeWidth},
+		{column: &column{header: "Trend/Lap"}, width: 11},
+		{column: &column{header: "DRS"}, width: 11},
+	}
+
+	lines := []string{s.renderHeader(columns)}
+	for _, battle := range battles {
+		driver := drivers[battle.Driver]
+		ahead := drivers[battle.Ahead]
+		gap := battleGap(battle, drivers)
+
+		names := cell{
+			{text: driver.ShortName, color: driver.HexColor},
+			{text: " vs "},
+			{text: ahead.ShortName, color: ahead.HexColor},
+		}
+
+		drs := text("", "")
+		if driver.DRSOpen {
+			drs = text("Open", "#00FF00")
+		} else if gap < time.Second && drsEnabled == Messages.DRSEnabled {
+			drs = text("Available", "#00FF00")
+		}
+
+		lines = append(lines, renderCells(columns, []cell{
+			names,
+			text(fmt.Sprintf("%d", battle.Laps), ""),
+			text(fmtDuration(gap), ""),
+			text(fmtDelta(battle.Trend()), deltaColor(battle.Trend())),
+			drs,
+		}, html))
+	}
+
+	s.battleLock.Lock()
+	alerts := append([]battleAlert(nil), s.battleAlerts...)
+	s.battleLock.Unlock()
+
+	// Alerts stay up for a while so they aren't missed
+	for _, alert := range alerts {
+		if now.Sub(alert.timestamp) > battleAlertDuration {
+			continue
+		}
+
+		message := fmt.Sprintf("%s - %s is %.2fs behind %s", alert.timestamp.In(s.f.CircuitTimezone()).Format("15:04:05"),
+			drivers[alert.driver].ShortName, alert.gap.Seconds(), drivers[alert.ahead].ShortName)
+		lines = append(lines, renderSpans(text(message, "#FFFF00"), html))
+	}
+
+	return lines
+}
@@ -0,0 +1,61 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+	"time"
+)
+
+func TestBattles(t *testing.T) {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+	data.Add(testEvent(Messages.Race, Messages.Started))
+
+	// HAM closes in on VER a tenth a lap
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	for lap := 1; lap <= 4; lap++ {
+		leader.Lap = lap
+		second.Lap = lap
+		second.TimeDiffToPositionAhead = time.Duration(1000-lap*100) * time.Millisecond
+		data.Add(Messages.EventTime{Timestamp: sessionStart.Add(time.Duration(lap) * 90 * time.Second), Remaining: time.Hour},
+			leader, second)
+	}
+
+	settings := raceColumns("Pos", "Driver", "Gap")
+	settings.Battles.Alerts = true
+	session, _ := playRace(t, data, settings)
+
+	// Closing to under half a second during the next lap raises an alert as the timing arrives, even when the
+	// tower isn't displayed
+	session.Update(keyMsg("g"))
+	second.TimeDiffToPositionAhead = 450 * time.Millisecond
+	data.Add(Messages.EventTime{Timestamp: sessionStart.Add(6 * time.Minute), Remaining: time.Hour}, second)
+	data.Play()
+	session.View()
+	session.battleLock.Lock()
+	alerts := len(session.battleAlerts)
+	session.battleLock.Unlock()
+	if alerts != 1 {
+		t.Fatalf("expected an alert, got %d", alerts)
+	}
+
+	session.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	checkGolden(t, "battles", session.View())
+}
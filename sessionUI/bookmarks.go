@@ -0,0 +1,284 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/json"
+	"errors"
+	"f1gopher/f1gopher-cmdline/ui"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Bookmark is a moment in a replay to jump back to
+type Bookmark struct {
+	Time time.Time `json:"time"`
+	Lap  int       `json:"lap"`
+	Note string    `json:"note,omitempty"`
+	// Made from a race control message rather than by the user, these aren't saved
+	automatic bool
+}
+
+// Bookmarks are the moments saved for one session, kept in time order
+type Bookmarks struct {
+	path    string
+	entries []Bookmark
+}
+
+// BookmarkPath is the file the bookmarks for a session are saved in under the cache directory
+func BookmarkPath(cache string, event f1gopherlib.RaceEvent) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' {
+			return r
+		}
+		return '_'
+	}, fmt.Sprintf("%s %s %s", event.EventTime.Format("2006-01-02"), event.Name, event.Type.String()))
+
+	return filepath.Join(cache, "bookmarks", name+".json")
+}
+
+// LoadBookmarks reads the bookmarks saved in a file, there are none if the file doesn't exist
+func LoadBookmarks(path string) (*Bookmarks, error) {
+	bookmarks := &Bookmarks{path: path}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return bookmarks, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &bookmarks.entries); err != nil {
+		return nil, fmt.Errorf("invalid bookmarks file %s: %v", path, err)
+	}
+	sort.SliceStable(bookmarks.entries, func(i, j int) bool {
+		return bookmarks.entries[i].Time.Before(bookmarks.entries[j].Time)
+	})
+
+	return bookmarks, nil
+}
+
+func (b *Bookmarks) Entries() []Bookmark {
+	return append([]Bookmark(nil), b.entries...)
+}
+
+// Add saves a bookmark in time order. The bookmarks are only changed once they have been written to the file.
+func (b *Bookmarks) Add(bookmark Bookmark) error {
+	x := sort.Search(len(b.entries), func(i int) bool {
+		return b.entries[i].Time.After(bookmark.Time)
+	})
+
+	entries := make([]Bookmark, 0, len(b.entries)+1)
+	entries = append(entries, b.entries[:x]...)
+	entries = append(entries, bookmark)
+	entries = append(entries, b.entries[x:]...)
+	return b.save(entries)
+}
+
+// Remove deletes the first saved bookmark at the time
+func (b *Bookmarks) Remove(timestamp time.Time) error {
+	for x := range b.entries {
+		if b.entries[x].Time.Equal(timestamp) {
+			entries := make([]Bookmark, 0, len(b.entries)-1)
+			entries = append(entries, b.entries[:x]...)
+			entries = append(entries, b.entries[x+1:]...)
+			return b.save(entries)
+		}
+	}
+	return nil
+}
+
+// save writes the bookmarks to the file and keeps them if that worked
+func (b *Bookmarks) save(entries []Bookmark) error {
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(b.path, data, 0644); err != nil {
+		return err
+	}
+
+	b.entries = entries
+	return nil
+}
+
+// automaticBookmark is the note for a race control message worth bookmarking: safety cars, red flags and penalties
+func automaticBookmark(msg Messages.RaceControlMessage) (string, bool) {
+	text := strings.ToUpper(msg.Msg)
+
+	switch {
+	case strings.Contains(text, "VIRTUAL SAFETY CAR DEPLOYED"):
+		return "Virtual safety car", true
+	case strings.Contains(text, "SAFETY CAR DEPLOYED"):
+		return "Safety car", true
+	case strings.Contains(text, "RED FLAG"):
+		return "Red flag", true
+	case strings.Contains(text, "PENALTY") && !strings.Contains(text, "SERVED") &&
+		!strings.Contains(text, "NO FURTHER ACTION"):
+		return "Penalty", true
+	default:
+		return "", false
+	}
+}
+
+// SetBookmarks gives the session the bookmarks saved for it, without them bookmarks can't be added
+func (s *sessionBase) SetBookmarks(bookmarks *Bookmarks) {
+	s.bookmarks = bookmarks
+}
+
+// startBookmark remembers the current moment and asks for a note to go with it
+func (s *sessionBase) startBookmark() {
+	s.eventLock.Lock()
+	s.pendingBookmark = Bookmark{Time: s.eventTime, Lap: s.event.CurrentLap}
+	s.eventLock.Unlock()
+
+	s.openPrompt(bookmarkPrompt)
+}
+
+// addBookmark saves the remembered moment with the note typed into the prompt
+func (s *sessionBase) addBookmark(note string) error {
+	bookmark := s.pendingBookmark
+	bookmark.Note = strings.TrimSpace(note)
+	return s.bookmarks.Add(bookmark)
+}
+
+// bookmarkList is the saved bookmarks and those made from race control messages so far, in time order
+func (s *sessionBase) bookmarkList() []Bookmark {
+	var result []Bookmark
+	if s.bookmarks != nil {
+		result = s.bookmarks.Entries()
+	}
+
+	s.rcMessagesLock.Lock()
+	result = append(result, s.autoBookmarks...)
+	s.rcMessagesLock.Unlock()
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Time.Before(result[j].Time)
+	})
+	return result
+}
+
+// updateBookmarks handles the keys for the bookmarks page, false if the key isn't used by the page
+func (s *sessionBase) updateBookmarks(msg tea.KeyMsg) bool {
+	bookmarks := s.bookmarkList()
+	s.bookmarkCursor = max(0, min(s.bookmarkCursor, len(bookmarks)-1))
+	s.bookmarkError = ""
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		s.page = s.ui
+
+	case tea.KeyUp:
+		s.bookmarkCursor = max(0, s.bookmarkCursor-1)
+
+	case tea.KeyDown:
+		s.bookmarkCursor = max(0, min(len(bookmarks)-1, s.bookmarkCursor+1))
+
+	case tea.KeyEnter:
+		if len(bookmarks) == 0 {
+			return true
+		}
+		if err := s.seek(seekTarget{time: bookmarks[s.bookmarkCursor].Time}); err != nil {
+			s.bookmarkError = err.Error()
+			return true
+		}
+		s.page = s.ui
+
+	case tea.KeyDelete, tea.KeyBackspace:
+		if len(bookmarks) == 0 || bookmarks[s.bookmarkCursor].automatic {
+			return true
+		}
+		if err := s.bookmarks.Remove(bookmarks[s.bookmarkCursor].Time); err != nil {
+			s.bookmarkError = err.Error()
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// bookmarksView lists the bookmarks for the terminal or the web server
+func (s *sessionBase) bookmarksView(html bool) string {
+	bookmarks := s.bookmarkList()
+
+	columns := []layoutColumn{
+		{column: &column{fill: true}, width: 2},
+		{column: &column{header: "Lap"}, width: 5},
+		{column: &column{header: "Time"}, width: 10},
+		{column: &column{header: "Bookmark", fill: true}, width: 80},
+	}
+	separator := columnSeparator(columns)
+
+	lines := []string{"Bookmarks", s.renderHeader(columns), separator}
+	for x, bookmark := range bookmarks {
+		note := text(" "+bookmark.Note, "")
+		if bookmark.automatic {
+			note = text(" "+bookmark.Note, "#FFFF00")
+		}
+
+		marker := text("", "")
+		if !html && x == s.bookmarkCursor {
+			marker = text(">", "")
+		}
+
+		lines = append(lines, renderCells(columns, []cell{
+			marker,
+			text(fmt.Sprintf("%d", bookmark.Lap), ""),
+			text(bookmark.Time.In(s.f.CircuitTimezone()).Format("15:04:05"), ""),
+			note,
+		}, html))
+	}
+	if len(bookmarks) == 0 {
+		lines = append(lines, "No bookmarks yet, press b on the timing tower to add one")
+	}
+	lines = append(lines, separator)
+
+	if s.bookmarks == nil {
+		lines = append(lines, renderSpans(text("Bookmarks can't be saved for this session", "#FFFF00"), html))
+	}
+	if len(s.bookmarkError) > 0 {
+		lines = append(lines, renderSpans(text(s.bookmarkError, "#FF0000"), html))
+	}
+
+	if !html {
+		lines = append(lines, "Up/Down: select, Enter: jump to the bookmark, Delete: remove, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// openBookmarks shows the bookmarks page
+func (s *sessionBase) openBookmarks() {
+	s.bookmarkCursor = 0
+	s.bookmarkError = ""
+	s.page = ui.Bookmarks
+}
@@ -0,0 +1,135 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadBookmarks(t *testing.T) {
+	event := f1gopherlib.RaceEvent{Name: "British Grand Prix", Type: Messages.RaceSession, EventTime: sessionStart}
+	path := BookmarkPath(t.TempDir(), event)
+	if filepath.Base(path) != "2023-07-09_British_Grand_Prix_Race.json" {
+		t.Errorf("unexpected bookmark file: %s", path)
+	}
+
+	bookmarks, err := LoadBookmarks(path)
+	if err != nil || len(bookmarks.Entries()) != 0 {
+		t.Fatalf("expected no bookmarks, got %v %v", bookmarks, err)
+	}
+
+	bookmarks.Add(Bookmark{Time: sessionStart.Add(30 * time.Minute), Lap: 20, Note: "pass"})
+	bookmarks.Add(Bookmark{Time: sessionStart.Add(10 * time.Minute), Lap: 5})
+
+	bookmarks, err = LoadBookmarks(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := bookmarks.Entries()
+	if len(entries) != 2 || entries[0].Lap != 5 || entries[1].Note != "pass" {
+		t.Errorf("unexpected bookmarks: %+v", entries)
+	}
+
+	// Bookmarks that can't be written aren't kept so trying again doesn't add them twice
+	blocked := filepath.Join(t.TempDir(), "blocked")
+	os.WriteFile(blocked, nil, 0644)
+	bookmarks = &Bookmarks{path: filepath.Join(blocked, "bookmarks.json")}
+	if err = bookmarks.Add(Bookmark{Time: sessionStart, Lap: 1}); err == nil || len(bookmarks.Entries()) != 0 {
+		t.Errorf("expected the bookmark not to be kept, got %+v %v", bookmarks.Entries(), err)
+	}
+}
+
+func TestAutomaticBookmark(t *testing.T) {
+	for msg, expected := range map[string]string{
+		"SAFETY CAR DEPLOYED":         "Safety car",
+		"VIRTUAL SAFETY CAR DEPLOYED": "Virtual safety car",
+		"RED FLAG":                    "Red flag",
+		"FIA STEWARDS: 5 SECOND TIME PENALTY FOR CAR 1 (VER) - CAUSING A COLLISION": "Penalty",
+		"FIA STEWARDS: PENALTY SERVED - 5 SECOND TIME PENALTY FOR CAR 1 (VER)":      "",
+		"YELLOW IN TRACK SECTOR 6": "",
+	} {
+		if note, _ := automaticBookmark(Messages.RaceControlMessage{Msg: msg}); note != expected {
+			t.Errorf("expected \"%s\" for %s, got \"%s\"", expected, msg, note)
+		}
+	}
+}
+
+func TestBookmarks(t *testing.T) {
+	data := raceScript()
+	data.Add(
+		Messages.EventTime{Timestamp: sessionStart.Add(30 * time.Minute), Remaining: 90 * time.Minute},
+		Messages.RaceControlMessage{Timestamp: sessionStart.Add(25 * time.Minute), Msg: "SAFETY CAR DEPLOYED", Flag: Messages.YellowFlag})
+
+	var reopened *fakeSession.Session
+	bookmarks, err := LoadBookmarks(filepath.Join(t.TempDir(), "bookmarks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	session.SetBookmarks(bookmarks)
+	session.SetReplaySource(func() f1gopherlib.F1GopherLib {
+		reopened = raceScript()
+		return reopened
+	})
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+	data.Play()
+
+	session.Update(keyMsg("b"))
+	for _, r := range "pass" {
+		session.Update(keyMsg(string(r)))
+	}
+	session.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if entries := bookmarks.Entries(); len(entries) != 1 || entries[0].Lap != 12 || entries[0].Note != "pass" ||
+		!entries[0].Time.Equal(sessionStart.Add(30*time.Minute)) {
+		t.Fatalf("unexpected bookmarks: %+v", entries)
+	}
+
+	if page, _ := session.Update(keyMsg("B")); page != ui.Bookmarks {
+		t.Fatalf("expected the bookmarks, got %v", page)
+	}
+	session.Update(tea.KeyMsg{Type: tea.KeyDown})
+	checkGolden(t, "bookmarks", session.View())
+
+	// Saved bookmarks can be removed, those from race control can't
+	session.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	session.Update(tea.KeyMsg{Type: tea.KeyUp})
+	session.Update(tea.KeyMsg{Type: tea.KeyDelete})
+	if len(bookmarks.Entries()) != 0 || len(session.bookmarkList()) != 1 {
+		t.Errorf("expected only the safety car bookmark to be left, got %+v", session.bookmarkList())
+	}
+
+	// Jumping to the safety car goes back to the start of the replay and skips ahead
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEnter}); page != ui.Replay {
+		t.Errorf("expected the timing tower, got %v", page)
+	}
+	if reopened == nil {
+		t.Fatal("expected the replay to be opened again")
+	}
+	followSeek(t, session, reopened, 0, 0)
+	if increments := reopened.TimeIncrements(); len(increments) != 5 || increments[0] != seekStep {
+		t.Errorf("unexpected time increments: %v", increments)
+	}
+}
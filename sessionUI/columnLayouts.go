@@ -0,0 +1,134 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// ColumnSetting is a column to display and the width to display it at, a width of zero uses the column's default
+type ColumnSetting struct {
+	Name  string `json:"name"`
+	Width int    `json:"width,omitempty"`
+}
+
+// ColumnLayouts are the columns displayed in the timing tower for each type of session
+type ColumnLayouts struct {
+	Race               []ColumnSetting `json:"race"`
+	PracticeQualifying []ColumnSetting `json:"practiceQualifying"`
+
+	path string
+}
+
+func defaultRaceColumns() []ColumnSetting {
+	return columnSettings("Pos", "Driver", "Segment", "Fastest", "Gap", "S1", "S2", "S3", "Last Lap", "DRS", "Tire",
+		"Lap", "Pitstops", "Speed Trap", "Location")
+}
+
+func defaultPracticeQualifyingColumns() []ColumnSetting {
+	return columnSettings("Pos", "Driver", "Segment", "Fastest", "Gap", "S1", "S2", "S3", "Cutoff", "Last Lap", "Tire",
+		"Lap", "Speed Trap", "Location")
+}
+
+func columnSettings(names ...string) []ColumnSetting {
+	result := make([]ColumnSetting, 0, len(names))
+	for _, name := range names {
+		result = append(result, ColumnSetting{Name: name})
+	}
+	return result
+}
+
+// NewColumnLayouts returns the default layouts which will be saved to path when changed. If path is empty the
+// layouts are never saved.
+func NewColumnLayouts(path string) *ColumnLayouts {
+	return &ColumnLayouts{
+		Race:               defaultRaceColumns(),
+		PracticeQualifying: defaultPracticeQualifyingColumns(),
+		path:               path,
+	}
+}
+
+// LoadColumnLayouts reads the layouts from path, using the defaults if the file doesn't exist
+func LoadColumnLayouts(path string) (*ColumnLayouts, error) {
+	layouts := NewColumnLayouts(path)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return layouts, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	loaded := ColumnLayouts{}
+	if err = json.Unmarshal(data, &loaded); err != nil {
+		return nil, fmt.Errorf("invalid column layout file %s: %v", path, err)
+	}
+
+	if loaded.Race != nil {
+		if err = validateColumns(loaded.Race, true); err != nil {
+			return nil, fmt.Errorf("invalid column layout file %s: %v", path, err)
+		}
+		layouts.Race = loaded.Race
+	}
+
+	if loaded.PracticeQualifying != nil {
+		if err = validateColumns(loaded.PracticeQualifying, false); err != nil {
+			return nil, fmt.Errorf("invalid column layout file %s: %v", path, err)
+		}
+		layouts.PracticeQualifying = loaded.PracticeQualifying
+	}
+
+	return layouts, nil
+}
+
+func validateColumns(settings []ColumnSetting, isRace bool) error {
+	for _, setting := range settings {
+		c := findColumn(setting.Name)
+		if c == nil {
+			return fmt.Errorf("unknown column \"%s\"", setting.Name)
+		}
+
+		if c.raceOnly && !isRace {
+			return fmt.Errorf("column \"%s\" is only available for races", setting.Name)
+		}
+		if c.qualifyingOnly && isRace {
+			return fmt.Errorf("column \"%s\" is only available for qualifying", setting.Name)
+		}
+
+		if setting.Width < 0 {
+			return fmt.Errorf("column \"%s\" has a negative width", setting.Name)
+		}
+	}
+	return nil
+}
+
+// Save writes the layouts to the file they were loaded from
+func (c *ColumnLayouts) Save() error {
+	if len(c.path) == 0 {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(c.path, data, 0644)
+}
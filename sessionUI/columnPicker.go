@@ -0,0 +1,208 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type pickerEntry struct {
+	column  *column
+	enabled bool
+	width   int
+}
+
+// columnPicker lets the user choose, order and size the columns for the current session type. Changes are
+// applied straight away and saved to the layout file.
+type columnPicker struct {
+	layouts *ColumnLayouts
+	isRace  bool
+	entries []pickerEntry
+	cursor  int
+	err     error
+}
+
+func newColumnPicker(layouts *ColumnLayouts, isRace bool) *columnPicker {
+	p := &columnPicker{
+		layouts: layouts,
+		isRace:  isRace,
+	}
+
+	// Displayed columns first in the order they are displayed followed by the rest of the registry
+	used := map[*column]bool{}
+	for _, setting := range p.settings() {
+		c := findColumn(setting.Name)
+		if c == nil || used[c] {
+			continue
+		}
+		used[c] = true
+		p.entries = append(p.entries, pickerEntry{column: c, enabled: true, width: setting.Width})
+	}
+
+	for _, c := range columnRegistry {
+		if !used[c] && (isRace && !c.qualifyingOnly || !isRace && !c.raceOnly) {
+			p.entries = append(p.entries, pickerEntry{column: c})
+		}
+	}
+
+	return p
+}
+
+func (p *columnPicker) settings() []ColumnSetting {
+	if p.isRace {
+		return p.layouts.Race
+	}
+	return p.layouts.PracticeQualifying
+}
+
+// update handles a key press and returns false when the picker has been closed
+func (p *columnPicker) update(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyEsc:
+		return false
+
+	case tea.KeyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+
+	case tea.KeyDown:
+		if p.cursor < len(p.entries)-1 {
+			p.cursor++
+		}
+
+	case tea.KeyShiftUp:
+		if p.cursor > 0 {
+			p.entries[p.cursor-1], p.entries[p.cursor] = p.entries[p.cursor], p.entries[p.cursor-1]
+			p.cursor--
+			p.apply()
+		}
+
+	case tea.KeyShiftDown:
+		if p.cursor < len(p.entries)-1 {
+			p.entries[p.cursor+1], p.entries[p.cursor] = p.entries[p.cursor], p.entries[p.cursor+1]
+			p.cursor++
+			p.apply()
+		}
+
+	case tea.KeySpace:
+		p.entries[p.cursor].enabled = !p.entries[p.cursor].enabled
+		p.apply()
+
+	default:
+		switch msg.String() {
+		case "c":
+			return false
+
+		case "+", "=":
+			p.resize(1)
+
+		case "-":
+			p.resize(-1)
+		}
+	}
+
+	return true
+}
+
+func (p *columnPicker) resize(change int) {
+	entry := &p.entries[p.cursor]
+
+	// The segment columns are sized from the track
+	if entry.column.segments {
+		return
+	}
+
+	width := entry.width
+	if width == 0 {
+		width = entry.column.width
+	}
+	width += change
+
+	// Keep room for the padding and at least one character
+	if width < 3 {
+		return
+	}
+
+	if width == entry.column.width {
+		width = 0
+	}
+	entry.width = width
+	p.apply()
+}
+
+func (p *columnPicker) apply() {
+	settings := make([]ColumnSetting, 0, len(p.entries))
+	for _, entry := range p.entries {
+		if entry.enabled {
+			settings = append(settings, ColumnSetting{Name: entry.column.name, Width: entry.width})
+		}
+	}
+
+	if p.isRace {
+		p.layouts.Race = settings
+	} else {
+		p.layouts.PracticeQualifying = settings
+	}
+
+	p.err = p.layouts.Save()
+}
+
+func (p *columnPicker) View() string {
+	title := "Race Columns"
+	if !p.isRace {
+		title = "Practice/Qualifying Columns"
+	}
+
+	result := title + "\n\n"
+	for x, entry := range p.entries {
+		cursor := "  "
+		if x == p.cursor {
+			cursor = "> "
+		}
+
+		enabled := "[ ]"
+		if entry.enabled {
+			enabled = "[x]"
+		}
+
+		width := "auto"
+		if !entry.column.segments {
+			if entry.width > 0 {
+				width = fmt.Sprintf("%d", entry.width)
+			} else {
+				width = fmt.Sprintf("%d", entry.column.width)
+			}
+		}
+
+		line := fmt.Sprintf("%s%s %-12s %s", cursor, enabled, entry.column.name, width)
+		if x == p.cursor {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00")).Render(line)
+		}
+		result += line + "\n"
+	}
+
+	result += "\nSpace: show/hide, Shift+Up/Down: move, +/-: width, c/Esc: close"
+
+	if p.err != nil {
+		result += "\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render(
+			fmt.Sprintf("Failed to save the column layout: %v", p.err))
+	}
+
+	return result
+}
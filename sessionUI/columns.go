@@ -0,0 +1,941 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"html"
+	"slices"
+	"strings"
+	"time"
+)
+
+const purple = "#D500D5"
+const cursorBackground = "#005F87"
+
+// span is a piece of text in a single color, an empty color uses the default text color
+type span struct {
+	text  string
+	color string
+}
+
+type cell []span
+
+func text(value string, color string) cell {
+	return cell{{text: value, color: color}}
+}
+
+// column describes one column of the timing tower. The same definition is used for the terminal and the web
+// server display.
+type column struct {
+	name   string
+	header string
+	width  int
+
+	// The value fills the whole column instead of being centered with padding either side
+	fill bool
+	// The width is set from the number of segments on the track and the column is the first to be collapsed
+	segments bool
+
+	// Columns with a higher priority are removed first when the terminal is too narrow, zero is never removed
+	priority int
+	// Header and width used when the terminal is too narrow, a zero width means the column can't be made narrower
+	shortHeader  string
+	compactWidth int
+
+	// Whether the column still has a value when the driver is out (stopped in a race or knocked out of qualifying)
+	showWhenOut bool
+	// Don't use the row background color, for columns that are colored themselves
+	noBackground bool
+	// Only available for races and sprints
+	raceOnly bool
+	// Only available for qualifying
+	qualifyingOnly bool
+
+	value  func(s *sessionBase, driver Messages.Timing) cell
+	footer func(s *sessionBase) cell
+	// Optional driver who set the session best in the footer
+	owner func(s *sessionBase) cell
+
+	// Optional narrower versions of the value and footer used with the compact width
+	compactValue  func(s *sessionBase, driver Messages.Timing) cell
+	compactFooter func(s *sessionBase) cell
+}
+
+// layoutColumn is a column with the width it is being displayed at
+type layoutColumn struct {
+	*column
+	width   int
+	compact bool
+}
+
+var columnRegistry = []*column{
+	{
+		name: "Pos", header: "Pos", width: 5, showWhenOut: true,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmt.Sprintf("%d", driver.Position), "")
+		},
+	},
+	{
+		name: "Number", header: "No", width: 4, showWhenOut: true,
+		priority: 7, shortHeader: "#",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmt.Sprintf("%d", driver.Number), "")
+		},
+	},
+	{
+		name: "Driver", header: "Driver", width: 8, showWhenOut: true, noBackground: true,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(driver.ShortName, driver.HexColor)
+		},
+	},
+	{
+		name: "Team", header: "Team", width: 18, showWhenOut: true, noBackground: true,
+		priority: 8, compactWidth: 12,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(driver.Team, driver.HexColor)
+		},
+	},
+	{
+		name: "Segment", header: "Segment", fill: true, segments: true, noBackground: true,
+		priority: 5, shortHeader: "S", compactWidth: 5,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return segmentsCell(driver.Segment[:s.segmentCount()], s.event.Sector1Segments, s.event.Sector2Segments)
+		},
+		footer: func(s *sessionBase) cell {
+			var flags cell
+			for x := 0; x < s.segmentCount(); x++ {
+				flags = append(flags, flagSpan(s.event.SegmentFlags[x]))
+
+				if x == s.event.Sector1Segments-1 || x == s.event.Sector1Segments+s.event.Sector2Segments-1 {
+					flags = append(flags, span{text: "|"})
+				}
+			}
+			return flags
+		},
+		compactValue: func(s *sessionBase, driver Messages.Timing) cell {
+			var sectors cell
+			for sector, segments := range s.sectorSegments() {
+				if sector > 0 {
+					sectors = append(sectors, span{text: "|"})
+				}
+				sectors = append(sectors, sectorSummary(driver.Segment[segments[0]:segments[1]]))
+			}
+			return sectors
+		},
+		compactFooter: func(s *sessionBase) cell {
+			var sectors cell
+			for sector, segments := range s.sectorSegments() {
+				if sector > 0 {
+					sectors = append(sectors, span{text: "|"})
+				}
+
+				// Show the worst flag in the sector
+				worst := Messages.NoFlag
+				for _, flag := range s.event.SegmentFlags[segments[0]:segments[1]] {
+					if flagSeverity(flag) > flagSeverity(worst) {
+						worst = flag
+					}
+				}
+				sectors = append(sectors, flagSpan(worst))
+			}
+			return sectors
+		},
+	},
+	{
+		name: "Best Segments", header: "Best", fill: true, segments: true, showWhenOut: true, noBackground: true,
+		priority: 7, shortHeader: "B", compactWidth: 5,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			best := s.driverBest(driver.Number)
+			return segmentsCell(best.segments[:s.segmentCount()], s.event.Sector1Segments, s.event.Sector2Segments)
+		},
+		compactValue: func(s *sessionBase, driver Messages.Timing) cell {
+			best := s.driverBest(driver.Number)
+			var sectors cell
+			for sector, segments := range s.sectorSegments() {
+				if sector > 0 {
+					sectors = append(sectors, span{text: "|"})
+				}
+				sectors = append(sectors, sectorSummary(best.segments[segments[0]:segments[1]]))
+			}
+			return sectors
+		},
+	},
+	{
+		name: "Fastest", header: "Fastest", width: timeWidth, showWhenOut: true,
+		priority: 2, shortHeader: "Best",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			if s.isRace() {
+				return text(fmtDuration(driver.FastestLap), fastestLapColor(driver.OverallFastestLap))
+			}
+			return text(fmtDuration(driver.FastestLap), "")
+		},
+	},
+	{
+		name: "Gap", header: "Gap", width: timeWidth,
+		priority: 1,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			if !s.isRace() {
+				gap := driver.TimeDiffToFastest
+				if s.gapToInfront.Load() {
+					gap = driver.TimeDiffToPositionAhead
+				}
+				return text(fmtDuration(gap), "")
+			}
+
+			gap := driver.GapToLeader
+			if s.gapToInfront.Load() {
+				gap = driver.TimeDiffToPositionAhead
+			}
+			return text(fmtDuration(gap), s.gapTrendColor(driver.Number))
+		},
+	},
+	{
+		name: "Interval", header: "Interval", width: timeWidth,
+		priority: 2, shortHeader: "Int",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmtDuration(driver.TimeDiffToPositionAhead), s.gapTrendColor(driver.Number))
+		},
+	},
+	{
+		name: "Leader", header: "Leader", width: timeWidth,
+		priority: 3, shortHeader: "Ldr",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			if s.isRace() {
+				return text(fmtDuration(driver.GapToLeader), "")
+			}
+			return text(fmtDuration(driver.TimeDiffToFastest), "")
+		},
+	},
+	{
+		name: "S1", header: "S1", width: timeWidth,
+		priority: 4,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmtDuration(driver.Sector1), timeColor(driver.Sector1PersonalFastest, driver.Sector1OverallFastest))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmtDuration(s.fastestSector1), purple)
+		},
+		owner: func(s *sessionBase) cell {
+			return s.ownerCell(s.fastestSectorDrivers[0])
+		},
+	},
+	{
+		name: "S2", header: "S2", width: timeWidth,
+		priority: 4,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmtDuration(driver.Sector2), timeColor(driver.Sector2PersonalFastest, driver.Sector2OverallFastest))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmtDuration(s.fastestSector2), purple)
+		},
+		owner: func(s *sessionBase) cell {
+			return s.ownerCell(s.fastestSectorDrivers[1])
+		},
+	},
+	{
+		name: "S3", header: "S3", width: timeWidth,
+		priority: 4,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmtDuration(driver.Sector3), timeColor(driver.Sector3PersonalFastest, driver.Sector3OverallFastest))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmtDuration(s.fastestSector3), purple)
+		},
+		owner: func(s *sessionBase) cell {
+			return s.ownerCell(s.fastestSectorDrivers[2])
+		},
+	},
+	{
+		name: "Last Lap", header: "Last Lap", width: timeWidth,
+		priority: 2, shortHeader: "Last",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmtDuration(driver.LastLap), timeColor(driver.LastLapPersonalFastest, driver.LastLapOverallFastest))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmtDuration(s.theoreticalFastestLap), purple)
+		},
+	},
+	{
+		name: "Ideal", header: "Ideal", width: timeWidth, showWhenOut: true,
+		priority: 5, shortHeader: "Idl",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			ideal := s.driverBest(driver.Number).ideal()
+			return text(fmtDuration(ideal), bestColor(ideal, ideal, s.towerValues.bestIdeal))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmtDuration(s.towerValues.bestIdeal), purple)
+		},
+		owner: func(s *sessionBase) cell {
+			return s.ownerCell(s.towerValues.bestIdealOwner)
+		},
+	},
+	{
+		name: "To Ideal", header: "To Ideal", width: 10, showWhenOut: true,
+		priority: 6, shortHeader: "ToI", compactWidth: 9,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			delta, exists := idealDelta(s.driverBest(driver.Number))
+			if !exists {
+				return nil
+			}
+			return text(fmtDelta(delta), "")
+		},
+	},
+	{
+		name: "DRS", header: "DRS", width: 8,
+		priority: 6,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			drs := "Closed"
+			if driver.DRSOpen {
+				drs = "Open"
+			}
+
+			drsColor := "#FFFFFF"
+			if driver.TimeDiffToPositionAhead > 0 && driver.TimeDiffToPositionAhead < time.Second {
+				drsColor = "#00FF00"
+			}
+			return text(drs, drsColor)
+		},
+	},
+	{
+		name: "Tire", header: "Tire", width: 10,
+		priority: 3, shortHeader: "T", compactWidth: 3,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(driver.Tire.String(), tireColor(driver.Tire))
+		},
+		compactValue: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(truncate(driver.Tire.String(), 1), tireColor(driver.Tire))
+		},
+	},
+	{
+		name: "Lap", header: "Lap", width: 5,
+		priority: 5, shortHeader: "TL",
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmt.Sprintf("%d", driver.LapsOnTire), "")
+		},
+	},
+	{
+		name: "Laps", header: "Laps", width: 6,
+		priority: 7, shortHeader: "L", compactWidth: 5,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmt.Sprintf("%d", driver.Lap), "")
+		},
+	},
+	{
+		name: "Pitstops", header: "Pitstops", width: 10,
+		priority: 6, shortHeader: "Pit", compactWidth: 5,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return text(fmt.Sprintf("%d", driver.Pitstops), "")
+		},
+	},
+	{
+		name: "Pit Time", header: "Pit Time", width: 10,
+		priority: 7, shortHeader: "PT", compactWidth: 6,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			if len(driver.PitStopTimes) == 0 {
+				return text("", "")
+			}
+			return text(fmt.Sprintf("%.1f", driver.PitStopTimes[len(driver.PitStopTimes)-1].PitlaneTime.Seconds()), "")
+		},
+	},
+	{
+		name: "Speed Trap", header: "Speed Trap", width: 12,
+		priority: 6, shortHeader: "Spd", compactWidth: 5,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			speedTrap := ""
+			if driver.SpeedTrap > 0 {
+				speedTrap = fmt.Sprintf("%d", driver.SpeedTrap)
+			}
+			return text(speedTrap, timeColor(driver.SpeedTrapPersonalFastest, driver.SpeedTrapOverallFastest))
+		},
+		footer: func(s *sessionBase) cell {
+			return text(fmt.Sprintf("%d", s.fastestSpeedTrap), purple)
+		},
+	},
+	{
+		name: "Grid +/-", header: "+/-", width: 5, showWhenOut: true, raceOnly: true,
+		priority: 6,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return s.gridCell(driver)
+		},
+	},
+	{
+		name: "Pace", header: "Pace", width: 18, raceOnly: true,
+		priority: 6, compactWidth: timeWidth,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			pace, exists := s.towerValues.paces[driver.Number]
+			if !exists {
+				return nil
+			}
+
+			result := text(fmtDuration(pace.Average), "")
+			if pace.HasDegradation {
+				result = append(result, span{text: " "})
+				result = append(result, degradationCell(pace.Degradation, true)...)
+			}
+			return result
+		},
+		compactValue: func(s *sessionBase, driver Messages.Timing) cell {
+			pace, exists := s.towerValues.paces[driver.Number]
+			if !exists {
+				return nil
+			}
+			return text(fmtDuration(pace.Average), "")
+		},
+	},
+	{
+		name: "Pit Rejoin", header: "Pit Rejoin", width: 22, raceOnly: true,
+		priority: 7, shortHeader: "Rejoin", compactWidth: 8,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			projection, exists := s.towerValues.pitProjections[driver.Number]
+			if !exists {
+				return nil
+			}
+			return pitRejoinCell(projection, false)
+		},
+		compactValue: func(s *sessionBase, driver Messages.Timing) cell {
+			projection, exists := s.towerValues.pitProjections[driver.Number]
+			if !exists {
+				return nil
+			}
+			return pitRejoinCell(projection, true)
+		},
+	},
+	{
+		name: "Cutoff", header: "Cutoff", width: 12, qualifyingOnly: true,
+		priority: 6, shortHeader: "Cut", compactWidth: 10,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			return cutoffCell(driver, s.towerValues.cutoff)
+		},
+		footer: func(s *sessionBase) cell {
+			cutoff := s.towerValues.cutoff.cutoff()
+			if cutoff == 0 {
+				return nil
+			}
+			return text(fmtDuration(cutoff), "#FFFF00")
+		},
+	},
+	{
+		name: "Location", header: "Location", width: 13, showWhenOut: true,
+		priority: 3, shortHeader: "Loc", compactWidth: 10,
+		value: func(s *sessionBase, driver Messages.Timing) cell {
+			if !s.isRace() && driver.KnockedOutOfQualifying {
+				return text("Out", "")
+			}
+			return text(driver.Location.String(), locationColor(driver.Location))
+		},
+	},
+}
+
+func flagSpan(flag Messages.FlagState) span {
+	switch flag {
+	case Messages.GreenFlag:
+		return span{text: "■", color: "#00FF00"}
+	case Messages.YellowFlag:
+		return span{text: "■", color: "#FFFF00"}
+	case Messages.DoubleYellowFlag:
+		return span{text: "■", color: "#FBFF00"}
+	case Messages.RedFlag:
+		return span{text: "■", color: "#FF0000"}
+	default:
+		return span{text: " "}
+	}
+}
+
+func flagSeverity(flag Messages.FlagState) int {
+	switch flag {
+	case Messages.GreenFlag:
+		return 1
+	case Messages.YellowFlag:
+		return 2
+	case Messages.DoubleYellowFlag:
+		return 3
+	case Messages.RedFlag:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// segmentsCell is a block for each segment colored by how fast it was with a divider between the sectors
+func segmentsCell(segments []Messages.SegmentType, sector1Segments int, sector2Segments int) cell {
+	var result cell
+	for x, segment := range segments {
+		switch segment {
+		case Messages.None:
+			result = append(result, span{text: " "})
+		default:
+			result = append(result, span{text: "■", color: string(segmentColor(segment))})
+		}
+
+		if x == sector1Segments-1 || x == sector1Segments+sector2Segments-1 {
+			result = append(result, span{text: "|"})
+		}
+	}
+	return result
+}
+
+// sectorSummary reduces the segments of a sector to one: purple if every completed segment was purple, otherwise
+// the first segment that wasn't green or purple, otherwise green
+func sectorSummary(segments []Messages.SegmentType) span {
+	completed := 0
+	purpleCount := 0
+	for _, segment := range segments {
+		switch segment {
+		case Messages.None:
+		case Messages.PurpleSegment:
+			completed++
+			purpleCount++
+		case Messages.GreenSegment:
+			completed++
+		default:
+			return span{text: "■", color: string(segmentColor(segment))}
+		}
+	}
+
+	if completed == 0 {
+		return span{text: " "}
+	}
+	if purpleCount == completed {
+		return span{text: "■", color: string(segmentColor(Messages.PurpleSegment))}
+	}
+	return span{text: "■", color: string(segmentColor(Messages.GreenSegment))}
+}
+
+// sectorSegments returns the start and end segment of each sector
+func (s *sessionBase) sectorSegments() [][2]int {
+	sector1 := s.event.Sector1Segments
+	sector2 := sector1 + s.event.Sector2Segments
+	total := s.event.TotalSegments
+	if sector2 > total {
+		sector2 = total
+	}
+	if sector1 > sector2 {
+		sector1 = sector2
+	}
+	return [][2]int{{0, sector1}, {sector1, sector2}, {sector2, total}}
+}
+
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if width < 0 {
+		width = 0
+	}
+	if len(runes) <= width {
+		return value
+	}
+	return string(runes[:width])
+}
+
+func findColumn(name string) *column {
+	for _, c := range columnRegistry {
+		if strings.EqualFold(c.name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+func (s *sessionBase) isRace() bool {
+	return s.f.Session() == Messages.RaceSession || s.f.Session() == Messages.SprintSession
+}
+
+// isOut is true for drivers that are stopped in a race or have been knocked out of qualifying
+func (s *sessionBase) isOut(driver Messages.Timing) bool {
+	if s.isRace() {
+		return driver.Location == Messages.Stopped
+	}
+	return driver.KnockedOutOfQualifying
+}
+
+func (s *sessionBase) segmentCount() int {
+	segmentCount := s.event.TotalSegments
+	if segmentCount == 0 {
+		segmentCount = len("Segment")
+	}
+	return segmentCount
+}
+
+// gapTrendColor is red if the car is dropping back from the car in front and green if it is catching
+func (s *sessionBase) gapTrendColor(number int) string {
+	gapColor := "#FFFFFF"
+	// The trend is for the live gaps so means nothing against the projected ones
+	if s.projecting {
+		return gapColor
+	}
+
+	s.driverGapLock.Lock()
+	trend, exists := s.driverGapTrend[number]
+	s.driverGapLock.Unlock()
+	if exists {
+		if trend.trend > 10 {
+			gapColor = "#FF0000"
+		} else if trend.trend < -10 {
+			gapColor = "#00FF00"
+		}
+	}
+	return gapColor
+}
+
+// columns returns the columns to display for the current session
+func (s *sessionBase) columns() []layoutColumn {
+	var settings []ColumnSetting
+	if s.isRace() {
+		settings = s.layouts.Race
+	} else {
+		settings = s.layouts.PracticeQualifying
+	}
+
+	result := make([]layoutColumn, 0, len(settings))
+	for _, setting := range settings {
+		c := findColumn(setting.Name)
+		if c == nil || (c.raceOnly && !s.isRace()) || (c.qualifyingOnly && s.f.Session() != Messages.QualifyingSession) {
+			continue
+		}
+
+		width := c.width
+		if setting.Width > 0 {
+			width = setting.Width
+		}
+		if c.segments {
+			width = s.segmentCount() + 2
+		}
+
+		result = append(result, layoutColumn{column: c, width: width})
+	}
+	return result
+}
+
+func tableWidth(columns []layoutColumn) int {
+	width := 0
+	for _, c := range columns {
+		width += c.width
+	}
+	if len(columns) > 1 {
+		width += len(columns) - 1
+	}
+	return width
+}
+
+// fitColumns makes the columns fit in the available width. First the segments are collapsed to one per sector,
+// then headers are abbreviated and columns narrowed and finally the lowest priority columns are removed.
+func fitColumns(columns []layoutColumn, available int) []layoutColumn {
+	if available <= 0 || tableWidth(columns) <= available {
+		return columns
+	}
+
+	result := make([]layoutColumn, len(columns))
+	copy(result, columns)
+
+	for x := range result {
+		if result[x].segments {
+			result[x].compact = true
+			result[x].width = result[x].compactWidth
+		}
+	}
+	if tableWidth(result) <= available {
+		return result
+	}
+
+	for x := range result {
+		result[x].compact = true
+		if result[x].compactWidth > 0 && result[x].compactWidth < result[x].width {
+			result[x].width = result[x].compactWidth
+		}
+	}
+
+	for tableWidth(result) > available {
+		// Remove all the columns with the lowest priority together so related columns (S1-S3) go at the same time
+		lowest := 0
+		for _, c := range result {
+			lowest = max(lowest, c.priority)
+		}
+		if lowest == 0 {
+			break
+		}
+
+		remaining := result[:0]
+		for _, c := range result {
+			if c.priority != lowest {
+				remaining = append(remaining, c)
+			}
+		}
+		result = remaining
+	}
+
+	return result
+}
+
+func columnSeparator(columns []layoutColumn) string {
+	return strings.Repeat("-", tableWidth(columns))
+}
+
+// towerValues are the calculations over the whole field that columns need for every row
+type towerValues struct {
+	pitProjections map[int]pitProjection
+	paces          map[int]history.StintPace
+	cutoff         cutoffPrediction
+	bestIdeal      time.Duration
+	bestIdealOwner int
+}
+
+// updateTowerValues works out the values shared by the tower columns before it is rendered, the HTML version of the
+// tower uses the same values
+func (s *sessionBase) updateTowerValues() {
+	values := towerValues{}
+	if s.isRace() {
+		values.pitProjections = s.pitProjections()
+		values.paces = s.currentPaces()
+	} else {
+		values.cutoff = s.predictCutoff()
+	}
+	values.bestIdeal, values.bestIdealOwner = s.bestIdeal()
+	s.towerValues = values
+}
+
+// renderTable renders the title, column headers and a row for each driver
+func (s *sessionBase) renderTable(title string, columns []layoutColumn, separator string, v []Messages.Timing, html bool) string {
+	table := title + "\n" + s.renderHeader(columns) + "\n" + separator + "\n"
+
+	for x, driver := range v {
+		background := ""
+		if s.rowBackground != nil {
+			background = s.rowBackground(x, driver)
+		}
+
+		// The cursor and drivers marked for comparison are only displayed in the terminal
+		if !html && s.isCompared(driver.Number) {
+			background = compareBackground
+		}
+		if !html && x == s.cursor {
+			background = cursorBackground
+		}
+
+		table += s.renderRow(columns, driver, background, html) + "\n"
+	}
+
+	return table
+}
+
+func (s *sessionBase) renderHeader(columns []layoutColumn) string {
+	cells := make([]string, 0, len(columns))
+	for _, c := range columns {
+		header := c.header
+		if c.compact && len(c.shortHeader) > 0 {
+			header = c.shortHeader
+		}
+		cells = append(cells, lipgloss.NewStyle().Align(lipgloss.Center).Width(c.width).Padding(0, 1, 0, 1).Render(truncate(header, c.width-2)))
+	}
+	return strings.Join(cells, "|")
+}
+
+// renderRow renders the row for a driver. An empty background leaves the background unchanged.
+func (s *sessionBase) renderRow(columns []layoutColumn, driver Messages.Timing, background string, html bool) string {
+	out := s.isOut(driver)
+
+	cells := make([]string, 0, len(columns))
+	for _, c := range columns {
+		var value cell
+		if !out || c.showWhenOut {
+			if c.compact && c.compactValue != nil {
+				value = c.compactValue(s, driver)
+			} else {
+				value = c.value(s, driver)
+			}
+		}
+
+		cellBackground := background
+		if c.noBackground {
+			cellBackground = ""
+		}
+
+		if html {
+			cells = append(cells, renderHTMLCell(c, value))
+		} else {
+			cells = append(cells, renderCell(c, value, cellBackground))
+		}
+	}
+
+	row := strings.Join(cells, "|")
+	if html && len(background) > 0 {
+		row = fmt.Sprintf("<span style=\"background-color: %s\">%s</span>", background, row)
+	}
+
+	if driver.ChequeredFlag && !out {
+		row = row + " 🏁"
+	}
+
+	return row
+}
+
+// renderFooter renders the track status and session bests underneath the matching columns
+func (s *sessionBase) renderFooter(columns []layoutColumn, html bool) string {
+	return renderFooterRow("Track Status:", columns, html,
+		func(c layoutColumn) bool { return c.footer != nil },
+		func(c layoutColumn) cell {
+			if c.compact && c.compactFooter != nil {
+				return c.compactFooter(s)
+			}
+			return c.footer(s)
+		})
+}
+
+// renderOwners renders the drivers who set the session bests underneath the matching columns, false if none of the
+// columns have an owner
+func (s *sessionBase) renderOwners(columns []layoutColumn, html bool) (string, bool) {
+	hasOwner := func(c layoutColumn) bool { return c.owner != nil }
+	if !slices.ContainsFunc(columns, hasOwner) {
+		return "", false
+	}
+
+	return renderFooterRow("Set By:", columns, html, hasOwner, func(c layoutColumn) cell {
+		return c.owner(s)
+	}), true
+}
+
+// renderFooterRow renders a row with a value for the columns that have one and a label in the space before them
+func renderFooterRow(label string, columns []layoutColumn, html bool, hasValue func(c layoutColumn) bool, value func(c layoutColumn) cell) string {
+	// The label takes up the space of the columns before the first one with a value
+	labelWidth := -1
+	first := 0
+	for ; first < len(columns) && !hasValue(columns[first]); first++ {
+		labelWidth += columns[first].width + 1
+	}
+
+	// And there is nothing to display after the last one with a value
+	last := len(columns) - 1
+	for ; last >= first && !hasValue(columns[last]); last-- {
+	}
+
+	cells := make([]string, 0, len(columns))
+	if first > 0 {
+		if labelWidth >= len(label) {
+			cells = append(cells, lipgloss.NewStyle().Width(labelWidth).Render(label))
+		} else {
+			cells = append(cells, strings.Repeat(" ", labelWidth))
+		}
+	}
+
+	for _, c := range columns[first : last+1] {
+		var content cell
+		if hasValue(c) {
+			content = value(c)
+		}
+
+		if html {
+			cells = append(cells, renderHTMLCell(c, content))
+		} else {
+			cells = append(cells, renderCell(c, content, ""))
+		}
+	}
+
+	return strings.Join(cells, "|")
+}
+
+// renderSpans joins the spans without any alignment or padding
+func renderSpans(value cell, html bool) string {
+	result := ""
+	for _, part := range value {
+		switch {
+		case html && len(part.color) > 0:
+			result += fmt.Sprintf("<font color=\"%s\">%s</font>", part.color, htmlText(part.text))
+		case html:
+			result += htmlText(part.text)
+		case len(part.color) > 0:
+			result += lipgloss.NewStyle().Foreground(lipgloss.Color(part.color)).Render(part.text)
+		default:
+			result += part.text
+		}
+	}
+	return result
+}
+
+func htmlText(value string) string {
+	return strings.ReplaceAll(html.EscapeString(value), "■", "&#x25a0;")
+}
+
+// renderCells renders a row of cells in the given columns
+func renderCells(columns []layoutColumn, values []cell, html bool) string {
+	cells := make([]string, 0, len(columns))
+	for x, c := range columns {
+		if html {
+			cells = append(cells, renderHTMLCell(c, values[x]))
+		} else {
+			cells = append(cells, renderCell(c, values[x], ""))
+		}
+	}
+	return strings.Join(cells, "|")
+}
+
+func renderCell(c layoutColumn, value cell, background string) string {
+	style := lipgloss.NewStyle().Align(lipgloss.Left).Width(c.width)
+	if !c.fill {
+		style = style.Align(lipgloss.Center).Padding(0, 1, 0, 1)
+	}
+	if len(background) > 0 {
+		style = style.Background(lipgloss.Color(background))
+	}
+
+	// Single colored values are styled as a whole so the padding gets the background too
+	if len(value) == 1 {
+		if len(value[0].color) > 0 {
+			style = style.Foreground(lipgloss.Color(value[0].color))
+		}
+		return style.Render(truncate(value[0].text, contentWidth(c)))
+	}
+
+	return style.Render(renderSpans(value, false))
+}
+
+// contentWidth is the width available for the value once the padding has been removed
+func contentWidth(c layoutColumn) int {
+	if c.fill {
+		return c.width
+	}
+	return c.width - 2
+}
+
+func renderHTMLCell(c layoutColumn, value cell) string {
+	width := contentWidth(c)
+	if len(value) == 1 {
+		value = cell{{text: truncate(value[0].text, width), color: value[0].color}}
+	}
+
+	textWidth := 0
+	for _, part := range value {
+		textWidth += lipgloss.Width(part.text)
+	}
+	content := renderSpans(value, true)
+
+	left, right := 0, 0
+	if gap := width - textWidth; gap > 0 {
+		if c.fill {
+			right = gap
+		} else {
+			left = gap / 2
+			right = gap - left
+		}
+	}
+	if !c.fill {
+		left++
+		right++
+	}
+
+	return strings.Repeat(" ", left) + content + strings.Repeat(" ", right)
+}
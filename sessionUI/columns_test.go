@@ -0,0 +1,102 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadColumnLayouts(t *testing.T) {
+	dir := t.TempDir()
+
+	layouts, err := LoadColumnLayouts(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts.Race) != len(defaultRaceColumns()) {
+		t.Errorf("expected the default race columns, got %v", layouts.Race)
+	}
+
+	path := filepath.Join(dir, "columns.json")
+	os.WriteFile(path, []byte(`{"race":[{"name":"Pos"},{"name":"driver","width":10}]}`), 0644)
+	layouts, err = LoadColumnLayouts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(layouts.Race) != 2 || layouts.Race[1].Width != 10 {
+		t.Errorf("unexpected race columns: %v", layouts.Race)
+	}
+	if len(layouts.PracticeQualifying) != len(defaultPracticeQualifyingColumns()) {
+		t.Errorf("expected the default practice columns, got %v", layouts.PracticeQualifying)
+	}
+
+	os.WriteFile(path, []byte(`{"race":[{"name":"Horsepower"}]}`), 0644)
+	if _, err = LoadColumnLayouts(path); err == nil {
+		t.Error("expected an error for an unknown column")
+	}
+
+	os.WriteFile(path, []byte(`{"practiceQualifying":[{"name":"Pace"}]}`), 0644)
+	if _, err = LoadColumnLayouts(path); err == nil {
+		t.Error("expected an error for a race only column in qualifying")
+	}
+}
+
+func TestColumnPicker(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "columns.json")
+	layouts := NewColumnLayouts(path)
+
+	data := raceScript()
+	settings := NewSettings()
+	settings.Layouts = layouts
+	session := NewRaceUI(&recordedHTML{}, settings)
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+
+	data.Play()
+
+	session.Update(keyMsg("c"))
+	if !strings.Contains(session.View(), "Race Columns") {
+		t.Fatal("expected the column picker to be displayed")
+	}
+
+	// Hide Pos, then move Driver to the end of the displayed columns and widen it
+	session.Update(tea.KeyMsg{Type: tea.KeySpace})
+	session.Update(tea.KeyMsg{Type: tea.KeyDown})
+	for x := 0; x < len(defaultRaceColumns())-2; x++ {
+		session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	}
+	session.Update(keyMsg("+"))
+	session.Update(keyMsg("c"))
+
+	header := strings.Split(session.View(), "\n")[1]
+	if strings.Contains(header, "Pos") || !strings.HasSuffix(header, "| Driver  ") {
+		t.Errorf("unexpected header: %q", header)
+	}
+
+	saved, err := LoadColumnLayouts(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := saved.Race[len(saved.Race)-1]
+	if len(saved.Race) != len(defaultRaceColumns())-1 || last.Name != "Driver" || last.Width != 9 {
+		t.Errorf("unexpected saved columns: %v", saved.Race)
+	}
+}
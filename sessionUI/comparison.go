@@ -0,0 +1,241 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sort"
+	"strings"
+	"time"
+)
+
+const compareBackground = "#5F5F00"
+
+// toggleCompare marks or unmarks a driver for the comparison, true once two drivers are marked
+func (s *sessionBase) toggleCompare(driverNumber int) bool {
+	for x, number := range s.compareDrivers {
+		if number == driverNumber {
+			s.compareDrivers = append(s.compareDrivers[:x], s.compareDrivers[x+1:]...)
+			return false
+		}
+	}
+
+	s.compareDrivers = append(s.compareDrivers, driverNumber)
+	return len(s.compareDrivers) == 2
+}
+
+func (s *sessionBase) isCompared(driverNumber int) bool {
+	for _, number := range s.compareDrivers {
+		if number == driverNumber {
+			return true
+		}
+	}
+	return false
+}
+
+// comparisonLaps returns the lap numbers completed by either driver, most recent first
+func comparisonLaps(a []history.Lap, b []history.Lap) []int {
+	seen := map[int]bool{}
+	var result []int
+	for _, laps := range [][]history.Lap{a, b} {
+		for _, lap := range laps {
+			if !seen[lap.Number] {
+				seen[lap.Number] = true
+				result = append(result, lap.Number)
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result
+}
+
+func findLap(laps []history.Lap, number int) (history.Lap, bool) {
+	for _, lap := range laps {
+		if lap.Number == number {
+			return lap, true
+		}
+	}
+	return history.Lap{}, false
+}
+
+// deltaCell is the difference between two times, blank unless both drivers have a time
+func deltaCell(a time.Duration, b time.Duration) cell {
+	if a == 0 || b == 0 {
+		return nil
+	}
+	return text(fmtDelta(a-b), deltaColor(a-b))
+}
+
+func lapTimeCell(value time.Duration, personalBest time.Duration) cell {
+	if value == 0 {
+		return nil
+	}
+	return text(fmtDuration(value), bestColor(value, personalBest, 0))
+}
+
+func isPitLap(lap history.Lap) bool {
+	return lap.PitIn || lap.PitOut
+}
+
+func tireCell(lap history.Lap) cell {
+	result := cell{{text: lap.Tire.String(), color: tireColor(lap.Tire)}, {text: fmt.Sprintf(" %d", lap.LapsOnTire)}}
+	if isPitLap(lap) {
+		result = append(result, span{text: " Pit"})
+	}
+	return result
+}
+
+// comparisonView displays two drivers side by side lap by lap. It is rendered for the terminal and the web
+// server so only the terminal version scrolls.
+func (s *sessionBase) comparisonView(html bool) string {
+	if len(s.compareDrivers) != 2 {
+		return "Two drivers haven't been marked to compare, press Esc to return to the timing tower"
+	}
+
+	s.dataLock.Lock()
+	driverA, existsA := s.data[s.compareDrivers[0]]
+	driverB, existsB := s.data[s.compareDrivers[1]]
+	s.dataLock.Unlock()
+
+	if !existsA || !existsB {
+		return "No timing for the selected drivers, press Esc to return to the timing tower"
+	}
+
+	lapsA := s.history.Laps(driverA.Number)
+	lapsB := s.history.Laps(driverB.Number)
+	bestsA := bestsFromLaps(lapsA)
+	bestsB := bestsFromLaps(lapsB)
+
+	var lines []string
+
+	lines = append(lines, renderSpans(cell{
+		{text: driverA.ShortName, color: driverA.HexColor},
+		{text: " vs "},
+		{text: driverB.ShortName, color: driverB.HexColor},
+	}, html))
+
+	for _, driver := range []Messages.Timing{driverA, driverB} {
+		bests := bestsA
+		if driver.Number == driverB.Number {
+			bests = bestsB
+		}
+		inProgress := driver.Location != Messages.Stopped && !driver.ChequeredFlag
+
+		summary := cell{{text: driver.ShortName, color: driver.HexColor},
+			{text: fmt.Sprintf(" P%d, Best Lap: %s, Ideal: %s, Stints: ",
+				driver.Position, strings.TrimSpace(fmtDuration(bests.lap)), strings.TrimSpace(fmtDuration(bests.ideal())))}}
+		summary = append(summary, stintSummary(s.history.CurrentStints(driver), inProgress)...)
+		lines = append(lines, renderSpans(summary, html))
+	}
+
+	// Average over the laps both drivers completed without stopping
+	laps := comparisonLaps(lapsA, lapsB)
+	var total time.Duration
+	count := 0
+	for _, number := range laps {
+		lapA, okA := findLap(lapsA, number)
+		lapB, okB := findLap(lapsB, number)
+		if okA && okB && lapA.Time > 0 && lapB.Time > 0 && !isPitLap(lapA) && !isPitLap(lapB) {
+			total += lapA.Time - lapB.Time
+			count++
+		}
+	}
+	average := cell{{text: "Average Lap Delta: "}}
+	if count > 0 {
+		average = append(average, span{text: fmtDelta(total / time.Duration(count)), color: deltaColor(total)},
+			span{text: fmt.Sprintf(" over %d laps", count)})
+	} else {
+		average = append(average, span{text: "no laps to compare"})
+	}
+	lines = append(lines, renderSpans(average, html))
+
+	columns := []layoutColumn{
+		{column: &column{header: "Lap"}, width: 5},
+		{column: &column{header: driverA.ShortName + " Time"}, width: timeWidth},
+		{column: &column{header: driverB.ShortName + " Time"}, width: timeWidth},
+		{column: &column{header: "Delta"}, width: timeWidth},
+		{column: &column{header: "S1 Delta"}, width: timeWidth},
+		{column: &column{header: "S2 Delta"}, width: timeWidth},
+		{column: &column{header: "S3 Delta"}, width: timeWidth},
+	}
+	// Gap to the leader is only the gap on track during a race
+	if s.isRace() {
+		columns = append(columns, layoutColumn{column: &column{header: "Gap"}, width: timeWidth})
+	}
+	columns = append(columns,
+		layoutColumn{column: &column{header: driverA.ShortName + " Tire"}, width: 16},
+		layoutColumn{column: &column{header: driverB.ShortName + " Tire"}, width: 16})
+
+	separator := columnSeparator(columns)
+	lines = append(lines, separator, s.renderHeader(columns), separator)
+
+	for x, number := range laps {
+		lapA, okA := findLap(lapsA, number)
+		lapB, okB := findLap(lapsB, number)
+
+		values := []cell{
+			text(fmt.Sprintf("%d", number), ""),
+			lapTimeCell(lapA.Time, bestsA.lap),
+			lapTimeCell(lapB.Time, bestsB.lap),
+			deltaCell(lapA.Time, lapB.Time),
+			deltaCell(lapA.Sector1, lapB.Sector1),
+			deltaCell(lapA.Sector2, lapB.Sector2),
+			deltaCell(lapA.Sector3, lapB.Sector3),
+		}
+
+		if s.isRace() {
+			var gap cell
+			if okA && okB {
+				// Colored by whether the first driver gained or lost time since the previous lap
+				color := "#FFFFFF"
+				if x+1 < len(laps) {
+					previousA, okA := findLap(lapsA, laps[x+1])
+					previousB, okB := findLap(lapsB, laps[x+1])
+					if okA && okB {
+						color = deltaColor((lapA.GapToLeader - lapB.GapToLeader) - (previousA.GapToLeader - previousB.GapToLeader))
+					}
+				}
+				gap = text(fmtDelta(lapA.GapToLeader-lapB.GapToLeader), color)
+			}
+			values = append(values, gap)
+		}
+
+		var tireA, tireB cell
+		if okA {
+			tireA = tireCell(lapA)
+		}
+		if okB {
+			tireB = tireCell(lapB)
+		}
+		values = append(values, tireA, tireB)
+
+		lines = append(lines, renderCells(columns, values, html))
+	}
+
+	if len(laps) == 0 {
+		lines = append(lines, "No completed laps")
+	}
+	lines = append(lines, separator)
+
+	if !html {
+		lines = s.scrollPage(lines, 7)
+		lines = append(lines, "Up/Down: scroll, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
 	"time"
 )
 
@@ -60,6 +61,27 @@ func fmtDuration(d time.Duration) string {
 	return fmt.Sprintf("%02d:%02d.%03d", minutes, seconds, milliseconds)
 }
 
+// fmtDelta formats the difference between two times with a sign, zero is displayed as no difference
+func fmtDelta(d time.Duration) string {
+	if d.Milliseconds() == 0 {
+		return "00.000"
+	}
+	if d < 0 {
+		return "-" + strings.TrimSpace(fmtDuration(-d))
+	}
+	return "+" + strings.TrimSpace(fmtDuration(d))
+}
+
+// deltaColor is green when the first driver is faster and red when they are slower
+func deltaColor(d time.Duration) string {
+	if d.Milliseconds() < 0 {
+		return "#00FF00"
+	} else if d.Milliseconds() > 0 {
+		return "#FF0000"
+	}
+	return "#FFFFFF"
+}
+
 func fmtCountdown(d time.Duration) string {
 	milliseconds := d.Milliseconds()
 
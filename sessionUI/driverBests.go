@@ -0,0 +1,114 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"time"
+)
+
+// driverBest is the best a driver has done in each sector and segment during the session. It is updated from every
+// timing update so sectors on laps that haven't been completed yet are included.
+type driverBest struct {
+	personalBests
+	segments [Messages.MaxSegments]Messages.SegmentType
+}
+
+// segmentRank orders the segment colors from slowest to fastest, segments that aren't timed rank lowest
+func segmentRank(segment Messages.SegmentType) int {
+	switch segment {
+	case Messages.PurpleSegment:
+		return 3
+	case Messages.GreenSegment:
+		return 2
+	case Messages.YellowSegment:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (b *driverBest) add(driver Messages.Timing) {
+	b.lap = fastest(b.lap, driver.FastestLap)
+	b.sector1 = fastest(b.sector1, driver.Sector1)
+	b.sector2 = fastest(b.sector2, driver.Sector2)
+	b.sector3 = fastest(b.sector3, driver.Sector3)
+	b.speedTrap = max(b.speedTrap, driver.SpeedTrap)
+
+	for x, segment := range driver.Segment {
+		if segmentRank(segment) > segmentRank(b.segments[x]) {
+			b.segments[x] = segment
+		}
+	}
+}
+
+// updateDriverBest adds the latest timing for a driver to their bests, the caller must hold fastestLock
+func (s *sessionBase) updateDriverBest(driver Messages.Timing) {
+	best, exists := s.driverBests[driver.Number]
+	if !exists {
+		best = &driverBest{}
+		s.driverBests[driver.Number] = best
+	}
+	best.add(driver)
+}
+
+// driverBest returns a copy of the bests for a driver
+func (s *sessionBase) driverBest(number int) driverBest {
+	s.fastestLock.Lock()
+	defer s.fastestLock.Unlock()
+
+	if best, exists := s.driverBests[number]; exists {
+		return *best
+	}
+	return driverBest{}
+}
+
+// bestIdeal is the fastest ideal lap of any driver and the driver it belongs to
+func (s *sessionBase) bestIdeal() (time.Duration, int) {
+	s.fastestLock.Lock()
+	defer s.fastestLock.Unlock()
+
+	var ideal time.Duration
+	owner := 0
+	for number, best := range s.driverBests {
+		if value := best.ideal(); value > 0 && (ideal == 0 || value < ideal || value == ideal && number < owner) {
+			ideal = value
+			owner = number
+		}
+	}
+	return ideal, owner
+}
+
+// ownerCell is the driver who set a session best, colored the same as their name
+func (s *sessionBase) ownerCell(number int) cell {
+	s.dataLock.Lock()
+	driver, exists := s.data[number]
+	s.dataLock.Unlock()
+
+	if !exists {
+		return nil
+	}
+	return text(driver.ShortName, driver.HexColor)
+}
+
+// idealDelta is how much slower the fastest lap is than the ideal lap, false until there are both
+func idealDelta(best driverBest) (time.Duration, bool) {
+	ideal := best.ideal()
+	if ideal == 0 || best.lap == 0 {
+		return 0, false
+	}
+	return best.lap - ideal, true
+}
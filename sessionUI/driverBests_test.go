@@ -0,0 +1,67 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+	"time"
+)
+
+func TestDriverBests(t *testing.T) {
+	data := raceScript()
+
+	// A quicker first sector on a slower lap improves the ideal lap and the best segments keep the fastest colors
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	leader.Sector1 = 29800 * time.Millisecond
+	leader.Sector2 = 41 * time.Second
+	leader.Sector3 = 21 * time.Second
+	leader.Segment[0] = Messages.YellowSegment
+	leader.Segment[2] = Messages.YellowSegment
+	leader.Segment[7] = Messages.GreenSegment
+	data.Add(leader)
+
+	session, _ := playRace(t, data, raceColumns("Pos", "Driver", "Best Segments", "Fastest", "S1", "Ideal", "To Ideal"))
+	checkGolden(t, "driver_bests", session.View())
+
+	if delta, exists := idealDelta(session.driverBest(1)); !exists || delta != 210*time.Millisecond {
+		t.Errorf("unexpected delta to the ideal lap: %v", delta)
+	}
+	if ideal, owner := session.bestIdeal(); ideal != 89850*time.Millisecond || owner != 1 {
+		t.Errorf("unexpected best ideal lap: %v by %d", ideal, owner)
+	}
+}
+
+func TestFastestSectorOwners(t *testing.T) {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+
+	// Only the second sector has been set
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	leader.Sector1 = 0
+	leader.Sector3 = 0
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	second.Sector1 = 0
+	second.Sector3 = 0
+	data.Add(testEvent(Messages.Race, Messages.Started), leader, second)
+
+	session, _ := playRace(t, data, raceColumns("Pos", "Driver", "S1", "S2", "S3"))
+	session.View()
+
+	if owners := session.fastestSectorDrivers; owners != [3]int{0, 1, 0} {
+		t.Errorf("unexpected fastest sector owners: %v", owners)
+	}
+}
@@ -0,0 +1,259 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"time"
+)
+
+// personalBests are the best times for one driver from their completed laps
+type personalBests struct {
+	lap       time.Duration
+	sector1   time.Duration
+	sector2   time.Duration
+	sector3   time.Duration
+	speedTrap int
+}
+
+func bestsFromLaps(laps []history.Lap) personalBests {
+	bests := personalBests{}
+	for _, lap := range laps {
+		bests.lap = fastest(bests.lap, lap.Time)
+		bests.sector1 = fastest(bests.sector1, lap.Sector1)
+		bests.sector2 = fastest(bests.sector2, lap.Sector2)
+		bests.sector3 = fastest(bests.sector3, lap.Sector3)
+		bests.speedTrap = max(bests.speedTrap, lap.SpeedTrap)
+	}
+	return bests
+}
+
+func fastest(best time.Duration, value time.Duration) time.Duration {
+	if value > 0 && (best == 0 || value < best) {
+		return value
+	}
+	return best
+}
+
+// ideal is the lap made from the best sectors, zero until there is a time for every sector
+func (p personalBests) ideal() time.Duration {
+	if p.sector1 == 0 || p.sector2 == 0 || p.sector3 == 0 {
+		return 0
+	}
+	return p.sector1 + p.sector2 + p.sector3
+}
+
+// bestColor colors a time purple if it is the fastest overall or green if it is the driver's personal best
+func bestColor(value time.Duration, personalBest time.Duration, overallBest time.Duration) string {
+	if value == 0 {
+		return "#FFFFFF"
+	}
+	return timeColor(value == personalBest, value == overallBest)
+}
+
+// driverRaceControlMessages returns the race control messages that mention the driver
+func (s *sessionBase) driverRaceControlMessages(driver Messages.Timing) []Messages.RaceControlMessage {
+	carNumber := fmt.Sprintf("CAR %d ", driver.Number)
+	shortName := fmt.Sprintf("(%s)", driver.ShortName)
+
+	s.rcMessagesLock.Lock()
+	defer s.rcMessagesLock.Unlock()
+
+	var result []Messages.RaceControlMessage
+	for _, msg := range s.rcMessages {
+		text := msg.Msg + " "
+		if strings.Contains(text, carNumber) || strings.Contains(text, shortName) {
+			result = append(result, msg)
+		}
+	}
+	return result
+}
+
+func lapNotes(lap history.Lap) string {
+	var notes []string
+	if lap.PitIn {
+		notes = append(notes, "Pit In")
+	}
+	if lap.PitOut {
+		notes = append(notes, "Pit Out")
+	}
+	if lap.SafetyCar {
+		notes = append(notes, "SC")
+	} else if lap.Yellow {
+		notes = append(notes, "Yellow")
+	}
+	return strings.Join(notes, ", ")
+}
+
+func stintSummary(stints []history.Stint, inProgress bool) cell {
+	var result cell
+	for x, stint := range stints {
+		if x > 0 {
+			result = append(result, span{text: ", "})
+		}
+
+		summary := ""
+		if stint.StartAge > 0 {
+			summary += fmt.Sprintf(" (used %d)", stint.StartAge)
+		}
+		summary += fmt.Sprintf(" laps %d-%d", stint.StartLap, stint.EndLap)
+		if inProgress && x == len(stints)-1 {
+			summary += " (current)"
+		}
+
+		result = append(result, span{text: stint.Tire.String(), color: tireColor(stint.Tire)}, span{text: summary})
+	}
+	return result
+}
+
+// scrollPage limits the lines to the height of the terminal, the first fixedLines are always displayed and the rest
+// scroll. A line is left free at the bottom for the help text.
+func (s *sessionBase) scrollPage(lines []string, fixedLines int) []string {
+	if s.currentHeight <= 0 || len(lines)+1 <= s.currentHeight || len(lines) < fixedLines {
+		return lines
+	}
+
+	scrollable := lines[fixedLines:]
+	visible := max(0, s.currentHeight-fixedLines-1)
+	s.pageScroll = min(s.pageScroll, max(0, len(scrollable)-visible))
+	return append(lines[:fixedLines:fixedLines], scrollable[s.pageScroll:min(len(scrollable), s.pageScroll+visible)]...)
+}
+
+// driverDetailView is the lap by lap history for the selected driver. It is rendered for the terminal and the web
+// server so only the terminal version scrolls.
+func (s *sessionBase) driverDetailView(html bool) string {
+	s.dataLock.Lock()
+	driver, exists := s.data[s.selectedDriver]
+	s.dataLock.Unlock()
+
+	if !exists {
+		return "No timing for the selected driver, press Esc to return to the timing tower"
+	}
+
+	laps := s.history.Laps(driver.Number)
+	bests := bestsFromLaps(laps)
+	stints := s.history.CurrentStints(driver)
+	inProgress := driver.Location != Messages.Stopped && !driver.ChequeredFlag
+
+	s.fastestLock.Lock()
+	overallSector1 := s.fastestSector1
+	overallSector2 := s.fastestSector2
+	overallSector3 := s.fastestSector3
+	overallSpeedTrap := s.fastestSpeedTrap
+	s.fastestLock.Unlock()
+
+	var lines []string
+
+	lines = append(lines, renderSpans(cell{
+		{text: fmt.Sprintf("#%d %s", driver.Number, driver.ShortName), color: driver.HexColor},
+		{text: fmt.Sprintf(" %s, Position: %d, Lap: %d, Tire: ", driver.Team, driver.Position, driver.Lap)},
+		{text: driver.Tire.String(), color: tireColor(driver.Tire)},
+		{text: fmt.Sprintf(" (%d laps), Pitstops: %d, Location: ", driver.LapsOnTire, driver.Pitstops)},
+		{text: driver.Location.String(), color: locationColor(driver.Location)},
+	}, html))
+
+	lines = append(lines, renderSpans(cell{
+		{text: "Best Lap: "},
+		{text: strings.TrimSpace(fmtDuration(bests.lap)), color: fastestLapColor(driver.OverallFastestLap)},
+		{text: ", S1: "},
+		{text: strings.TrimSpace(fmtDuration(bests.sector1)), color: timeColor(true, bests.sector1 == overallSector1)},
+		{text: ", S2: "},
+		{text: strings.TrimSpace(fmtDuration(bests.sector2)), color: timeColor(true, bests.sector2 == overallSector2)},
+		{text: ", S3: "},
+		{text: strings.TrimSpace(fmtDuration(bests.sector3)), color: timeColor(true, bests.sector3 == overallSector3)},
+		{text: ", Ideal: " + strings.TrimSpace(fmtDuration(bests.ideal())) + ", Speed Trap: "},
+		{text: fmt.Sprintf("%d", bests.speedTrap), color: timeColor(true, bests.speedTrap == overallSpeedTrap)},
+	}, html))
+
+	lines = append(lines, renderSpans(append(cell{{text: "Stints: "}}, stintSummary(stints, inProgress)...), html))
+
+	pitStops := "Pit Stops:"
+	if len(driver.PitStopTimes) == 0 {
+		pitStops += " none"
+	}
+	for x, pitStop := range driver.PitStopTimes {
+		if x > 0 {
+			pitStops += ","
+		}
+		pitStops += fmt.Sprintf(" Lap %d (%.1fs)", pitStop.Lap, pitStop.PitlaneTime.Seconds())
+	}
+	lines = append(lines, renderSpans(text(pitStops, ""), html))
+
+	columns := []layoutColumn{
+		{column: &column{header: "Lap"}, width: 5},
+		{column: &column{header: "Time"}, width: timeWidth},
+		{column: &column{header: "S1"}, width: timeWidth},
+		{column: &column{header: "S2"}, width: timeWidth},
+		{column: &column{header: "S3"}, width: timeWidth},
+		{column: &column{header: "Tire"}, width: 10},
+		{column: &column{header: "Age"}, width: 5},
+		{column: &column{header: "Pos"}, width: 5},
+		{column: &column{header: "Gap"}, width: timeWidth},
+		{column: &column{header: "Speed"}, width: 7},
+		{column: &column{header: "Notes"}, width: 16},
+	}
+
+	separator := columnSeparator(columns)
+	lines = append(lines, separator, s.renderHeader(columns), separator)
+
+	// Most recent lap first
+	for x := len(laps) - 1; x >= 0; x-- {
+		lap := laps[x]
+
+		var speedTrap cell
+		if lap.SpeedTrap > 0 {
+			speedTrap = text(fmt.Sprintf("%d", lap.SpeedTrap), timeColor(lap.SpeedTrap == bests.speedTrap, lap.SpeedTrap == overallSpeedTrap))
+		}
+
+		lines = append(lines, renderCells(columns, []cell{
+			text(fmt.Sprintf("%d", lap.Number), ""),
+			text(fmtDuration(lap.Time), bestColor(lap.Time, bests.lap, 0)),
+			text(fmtDuration(lap.Sector1), bestColor(lap.Sector1, bests.sector1, overallSector1)),
+			text(fmtDuration(lap.Sector2), bestColor(lap.Sector2, bests.sector2, overallSector2)),
+			text(fmtDuration(lap.Sector3), bestColor(lap.Sector3, bests.sector3, overallSector3)),
+			text(lap.Tire.String(), tireColor(lap.Tire)),
+			text(fmt.Sprintf("%d", lap.LapsOnTire), ""),
+			text(fmt.Sprintf("%d", lap.Position), ""),
+			text(fmtDuration(lap.GapToLeader), ""),
+			speedTrap,
+			text(lapNotes(lap), ""),
+		}, html))
+	}
+
+	if len(laps) == 0 {
+		lines = append(lines, "No completed laps")
+	}
+
+	lines = append(lines, separator, "Race Control:")
+	rcMessages := s.driverRaceControlMessages(driver)
+	if len(rcMessages) == 0 {
+		lines = append(lines, "No messages")
+	}
+	for x := len(rcMessages) - 1; x >= 0; x-- {
+		lines = append(lines, renderSpans(text(fmt.Sprintf("%s - %s",
+			rcMessages[x].Timestamp.In(s.f.CircuitTimezone()).Format("02-01-2006 15:04:05"), rcMessages[x].Msg), ""), html))
+	}
+
+	if !html {
+		lines = s.scrollPage(lines, 4)
+		lines = append(lines, "Up/Down: scroll, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,146 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"testing"
+	"time"
+)
+
+// lapsScript is a race where HAM completes laps 9 to 12, pitting at the end of lap 10
+func lapsScript() *fakeSession.Session {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+	data.Add(testEvent(Messages.Race, Messages.Started),
+		Messages.EventTime{Timestamp: sessionStart.Add(20 * time.Minute), Remaining: 100 * time.Minute})
+
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	leader.Tire = Messages.Hard
+	for lap := 9; lap <= 12; lap++ {
+		leader.Lap = lap
+		leader.LastLap = 91 * time.Second
+		leader.Sector1 = 30200 * time.Millisecond
+		leader.LapsOnTire = lap - 5
+		data.Add(leader)
+	}
+
+	driver := testDriver(2, 44, "HAM", "#6CD3BF")
+	driver.Team = "Mercedes"
+	for lap := 9; lap <= 12; lap++ {
+		driver.Lap = lap
+		driver.LastLap = 90*time.Second + time.Duration(lap)*100*time.Millisecond
+		driver.Sector1 = 30*time.Second + time.Duration(lap)*10*time.Millisecond
+		driver.SpeedTrap = 300 + lap
+		driver.GapToLeader = time.Duration(lap-8) * 700 * time.Millisecond
+		driver.Location = Messages.OnTrack
+
+		switch lap {
+		case 9:
+			driver.Tire = Messages.Medium
+			driver.LapsOnTire = 9
+		case 10:
+			driver.LapsOnTire++
+			driver.Location = Messages.Pitlane
+			driver.LastLap += 20 * time.Second
+		case 11:
+			driver.Tire = Messages.Hard
+			driver.LapsOnTire = 1
+			driver.Pitstops = 1
+			driver.PitStopTimes = []Messages.PitStop{{Lap: 10, PitlaneTime: 22400 * time.Millisecond}}
+		default:
+			driver.LapsOnTire++
+		}
+		data.Add(driver)
+	}
+
+	data.Add(Messages.RaceControlMessage{Timestamp: sessionStart.Add(10 * time.Minute), Msg: "CAR 44 (HAM) TIME 1:31.100 DELETED - TRACK LIMITS AT TURN 4 LAP 11"},
+		Messages.RaceControlMessage{Timestamp: sessionStart.Add(11 * time.Minute), Msg: "CAR 1 (VER) WARNED"})
+
+	return data
+}
+
+func TestDriverDetail(t *testing.T) {
+	data := lapsScript()
+
+	web := &recordedHTML{}
+	session := NewRaceUI(web, NewSettings())
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+	data.Play()
+	session.View()
+
+	// Select the second row and open it
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEnter}); page != ui.DriverDetail {
+		t.Fatalf("expected the driver detail page, got %v", page)
+	}
+	checkGolden(t, "driver_detail", session.View())
+	checkGolden(t, "driver_detail_html", web.String())
+
+	// Escape returns to the tower with the row still selected, then clears it, then leaves the session
+	for _, expected := range []ui.Page{ui.Replay, ui.Replay, ui.MainMenu} {
+		if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != expected {
+			t.Fatalf("expected %v after escape, got %v", expected, page)
+		}
+	}
+
+	// Up skips forward whether or not a row is selected
+	session.Update(tea.KeyMsg{Type: tea.KeyUp})
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	session.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if increments := data.TimeIncrements(); len(increments) != 2 || session.cursor != 0 {
+		t.Errorf("expected up to skip forward, got %v", increments)
+	}
+}
+
+func TestComparison(t *testing.T) {
+	data := lapsScript()
+
+	web := &recordedHTML{}
+	session := NewRaceUI(web, NewSettings())
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+	data.Play()
+	session.View()
+
+	// Mark HAM then VER, the second mark opens the comparison
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftDown})
+	if page, _ := session.Update(keyMsg("v")); page != ui.Replay {
+		t.Fatalf("expected to stay on the timing tower after marking one driver, got %v", page)
+	}
+	session.Update(tea.KeyMsg{Type: tea.KeyShiftUp})
+	if page, _ := session.Update(keyMsg("v")); page != ui.Comparison {
+		t.Fatalf("expected the comparison page, got %v", page)
+	}
+	checkGolden(t, "comparison", session.View())
+	checkGolden(t, "comparison_html", web.String())
+
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.Replay || len(session.compareDrivers) != 0 {
+		t.Fatalf("expected escape to return to the tower and clear the comparison, got %v", page)
+	}
+
+	// Without two marked drivers there is nothing to compare
+	session.page = ui.Comparison
+	if !strings.Contains(session.View(), "Two drivers haven't been marked") {
+		t.Error("expected the comparison to be empty")
+	}
+}
@@ -0,0 +1,404 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/ui"
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"html"
+	"strings"
+	"time"
+)
+
+// Number of drivers plotted when the chart is first opened
+const defaultChartDrivers = 5
+
+const svgWidth = 800
+const svgHeight = 400
+
+type gapPoint struct {
+	lap int
+	gap time.Duration
+}
+
+// gapSeries is the gap for one driver at the end of each lap
+type gapSeries struct {
+	driver Messages.Timing
+	points []gapPoint
+}
+
+// openGapChart displays the gap chart, the first time it is opened in a session the top drivers are plotted
+func (s *sessionBase) openGapChart() {
+	drivers := s.sortedDrivers()
+
+	if s.chartDrivers == nil {
+		s.chartDrivers = make(map[int]bool)
+		for x := 0; x < len(drivers) && x < defaultChartDrivers; x++ {
+			s.chartDrivers[drivers[x].Number] = true
+		}
+	}
+
+	if s.cursor >= 0 && s.cursor < len(drivers) {
+		s.chartDrivers[drivers[s.cursor].Number] = true
+		s.chartCursor = s.cursor
+	}
+
+	s.page = ui.GapChart
+}
+
+// updateGapChart handles the keys for the gap chart, false if the key isn't used by the chart
+func (s *sessionBase) updateGapChart(msg tea.KeyMsg) bool {
+	drivers := s.sortedDrivers()
+	if len(drivers) == 0 {
+		return false
+	}
+	s.chartCursor = min(s.chartCursor, len(drivers)-1)
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		s.page = s.ui
+
+	case tea.KeyLeft:
+		s.chartCursor = max(0, s.chartCursor-1)
+
+	case tea.KeyRight:
+		s.chartCursor = min(len(drivers)-1, s.chartCursor+1)
+
+	case tea.KeySpace:
+		number := drivers[s.chartCursor].Number
+		s.chartDrivers[number] = !s.chartDrivers[number]
+
+	case tea.KeyEnter:
+		// Selecting the reference driver again goes back to the gap to the leader
+		number := drivers[s.chartCursor].Number
+		if s.chartReference == number {
+			s.chartReference = 0
+		} else {
+			s.chartReference = number
+		}
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// gapSeries returns the gap at the end of each lap for the plotted drivers. The gap is to the leader or to the
+// reference driver when there is one, laps where either gap is unknown are skipped.
+func (s *sessionBase) gapSeries(drivers []Messages.Timing) []gapSeries {
+	reference := map[int]time.Duration{}
+	if s.chartReference != 0 {
+		for _, lap := range s.history.Laps(s.chartReference) {
+			if gap, ok := lapGap(lap.Position, lap.GapToLeader); ok {
+				reference[lap.Number] = gap
+			}
+		}
+	}
+
+	var result []gapSeries
+	for _, driver := range drivers {
+		if !s.chartDrivers[driver.Number] {
+			continue
+		}
+
+		series := gapSeries{driver: driver}
+		for _, lap := range s.history.Laps(driver.Number) {
+			gap, ok := lapGap(lap.Position, lap.GapToLeader)
+			if !ok {
+				continue
+			}
+
+			if s.chartReference != 0 {
+				referenceGap, exists := reference[lap.Number]
+				if !exists {
+					continue
+				}
+				gap -= referenceGap
+			}
+
+			series.points = append(series.points, gapPoint{lap: lap.Number, gap: gap})
+		}
+		result = append(result, series)
+	}
+	return result
+}
+
+// lapGap is false when the gap is missing, only the leader has no gap
+func lapGap(position int, gapToLeader time.Duration) (time.Duration, bool) {
+	return gapToLeader, position == 1 || gapToLeader > 0
+}
+
+// chartRange returns the laps and gaps covered by the series, zero gap is always included
+func chartRange(series []gapSeries) (firstLap int, lastLap int, minGap time.Duration, maxGap time.Duration) {
+	for _, s := range series {
+		for _, point := range s.points {
+			if firstLap == 0 || point.lap < firstLap {
+				firstLap = point.lap
+			}
+			lastLap = max(lastLap, point.lap)
+			minGap = min(minGap, point.gap)
+			maxGap = max(maxGap, point.gap)
+		}
+	}
+	return firstLap, lastLap, minGap, maxGap
+}
+
+// scale maps value from the range first to last on to 0 to size-1
+func scale(value int64, first int64, last int64, size int) int {
+	if last == first || size <= 1 {
+		return 0
+	}
+	return int((value - first) * int64(size-1) / (last - first))
+}
+
+// brailleCanvas is a grid of characters where each character is 2x4 braille dots
+type brailleCanvas struct {
+	width  int
+	height int
+	dots   [][]rune
+	colors [][]string
+}
+
+func newBrailleCanvas(width int, height int) *brailleCanvas {
+	c := &brailleCanvas{width: width, height: height}
+	c.dots = make([][]rune, height)
+	c.colors = make([][]string, height)
+	for y := range c.dots {
+		c.dots[y] = make([]rune, width)
+		c.colors[y] = make([]string, width)
+	}
+	return c
+}
+
+// Bit for each dot in a braille character indexed by row and then column
+var brailleDots = [4][2]rune{{0x01, 0x08}, {0x02, 0x10}, {0x04, 0x20}, {0x40, 0x80}}
+
+// set turns on a dot, the last color set in a character is used for the whole character
+func (c *brailleCanvas) set(x int, y int, color string) {
+	if x < 0 || y < 0 || x >= c.width*2 || y >= c.height*4 {
+		return
+	}
+	c.dots[y/4][x/2] |= brailleDots[y%4][x%2]
+	c.colors[y/4][x/2] = color
+}
+
+func (c *brailleCanvas) line(x0 int, y0 int, x1 int, y1 int, color string) {
+	dx := max(x1-x0, x0-x1)
+	dy := -max(y1-y0, y0-y1)
+	stepX := 1
+	if x0 > x1 {
+		stepX = -1
+	}
+	stepY := 1
+	if y0 > y1 {
+		stepY = -1
+	}
+
+	err := dx + dy
+	for {
+		c.set(x0, y0, color)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if 2*err >= dy {
+			err += dy
+			x0 += stepX
+		}
+		if 2*err <= dx {
+			err += dx
+			y0 += stepY
+		}
+	}
+}
+
+// rows returns each row of characters with a span for each run of the same color
+func (c *brailleCanvas) rows() []cell {
+	result := make([]cell, c.height)
+	for y := range c.dots {
+		for x, dots := range c.dots[y] {
+			char := " "
+			if dots != 0 {
+				char = string(0x2800 + dots)
+			}
+
+			row := result[y]
+			if len(row) > 0 && row[len(row)-1].color == c.colors[y][x] {
+				row[len(row)-1].text += char
+			} else {
+				row = append(row, span{text: char, color: c.colors[y][x]})
+			}
+			result[y] = row
+		}
+	}
+	return result
+}
+
+func fmtGap(gap time.Duration) string {
+	return fmt.Sprintf("%.1fs", gap.Seconds())
+}
+
+// gapChartLines draws the series with the gap increasing downwards so the drivers are in track order
+func gapChartLines(series []gapSeries, width int, height int) []string {
+	firstLap, lastLap, minGap, maxGap := chartRange(series)
+	if lastLap == 0 {
+		return []string{"No completed laps"}
+	}
+
+	labels := []string{fmtGap(minGap), fmtGap((minGap + maxGap) / 2), fmtGap(maxGap)}
+	labelWidth := 0
+	for _, label := range labels {
+		labelWidth = max(labelWidth, len(label))
+	}
+
+	canvas := newBrailleCanvas(max(1, width-labelWidth-2), height)
+	for _, s := range series {
+		for x, point := range s.points {
+			pointX := scale(int64(point.lap), int64(firstLap), int64(lastLap), canvas.width*2)
+			pointY := scale(int64(point.gap), int64(minGap), int64(maxGap), canvas.height*4)
+			if x == 0 {
+				canvas.set(pointX, pointY, s.driver.HexColor)
+				continue
+			}
+
+			previous := s.points[x-1]
+			canvas.line(
+				scale(int64(previous.lap), int64(firstLap), int64(lastLap), canvas.width*2),
+				scale(int64(previous.gap), int64(minGap), int64(maxGap), canvas.height*4),
+				pointX,
+				pointY,
+				s.driver.HexColor)
+		}
+	}
+
+	var lines []string
+	for y, row := range canvas.rows() {
+		label := ""
+		switch y {
+		case 0:
+			label = labels[0]
+		case height / 2:
+			label = labels[1]
+		case height - 1:
+			label = labels[2]
+		}
+		lines = append(lines, fmt.Sprintf("%*s |", labelWidth, label)+renderSpans(row, false))
+	}
+
+	lines = append(lines, strings.Repeat(" ", labelWidth+1)+"+"+strings.Repeat("-", canvas.width))
+	lapLabels := fmt.Sprintf("Lap %d", firstLap)
+	if lastLap != firstLap {
+		last := fmt.Sprintf("Lap %d", lastLap)
+		lapLabels += strings.Repeat(" ", max(1, canvas.width-len(lapLabels)-len(last))) + last
+	}
+	lines = append(lines, strings.Repeat(" ", labelWidth+2)+lapLabels)
+
+	return lines
+}
+
+// gapChartSVG is the same chart as an SVG image for the web server
+func gapChartSVG(series []gapSeries) string {
+	firstLap, lastLap, minGap, maxGap := chartRange(series)
+	if lastLap == 0 {
+		return "No completed laps"
+	}
+
+	const left = 60
+	const bottom = 30
+	plotWidth := svgWidth - left - 10
+	plotHeight := svgHeight - bottom - 10
+
+	var svg strings.Builder
+	fmt.Fprintf(&svg, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" font-family=\"monospace\" font-size=\"12\">\n", svgWidth, svgHeight)
+	fmt.Fprintf(&svg, "<line x1=\"%d\" y1=\"10\" x2=\"%d\" y2=\"%d\" stroke=\"#808080\"/>\n", left, left, 10+plotHeight)
+	fmt.Fprintf(&svg, "<line x1=\"%d\" y1=\"%d\" x2=\"%d\" y2=\"%d\" stroke=\"#808080\"/>\n", left, 10+plotHeight, left+plotWidth, 10+plotHeight)
+
+	for _, label := range []time.Duration{minGap, (minGap + maxGap) / 2, maxGap} {
+		y := 10 + scale(int64(label), int64(minGap), int64(maxGap), plotHeight)
+		fmt.Fprintf(&svg, "<text x=\"%d\" y=\"%d\" fill=\"#FFFFFF\" text-anchor=\"end\">%s</text>\n", left-5, y+4, fmtGap(label))
+	}
+	fmt.Fprintf(&svg, "<text x=\"%d\" y=\"%d\" fill=\"#FFFFFF\">Lap %d</text>\n", left, svgHeight-10, firstLap)
+	fmt.Fprintf(&svg, "<text x=\"%d\" y=\"%d\" fill=\"#FFFFFF\" text-anchor=\"end\">Lap %d</text>\n", left+plotWidth, svgHeight-10, lastLap)
+
+	for _, s := range series {
+		var points []string
+		for _, point := range s.points {
+			points = append(points, fmt.Sprintf("%d,%d",
+				left+scale(int64(point.lap), int64(firstLap), int64(lastLap), plotWidth),
+				10+scale(int64(point.gap), int64(minGap), int64(maxGap), plotHeight)))
+		}
+		fmt.Fprintf(&svg, "<polyline points=\"%s\" fill=\"none\" stroke=\"%s\" stroke-width=\"2\"><title>%s</title></polyline>\n",
+			strings.Join(points, " "), s.driver.HexColor, html.EscapeString(s.driver.ShortName))
+	}
+
+	svg.WriteString("</svg>")
+	return svg.String()
+}
+
+// gapChartView displays the gap chart for the terminal or the web server
+func (s *sessionBase) gapChartView(html bool) string {
+	drivers := s.sortedDrivers()
+	series := s.gapSeries(drivers)
+
+	title := "Gap Chart - Gap to the Leader"
+	if s.chartReference != 0 {
+		s.dataLock.Lock()
+		title = "Gap Chart - Gap to " + s.data[s.chartReference].ShortName
+		s.dataLock.Unlock()
+	}
+
+	// Every driver so they can be picked, the plotted drivers are in their color and the reference is marked
+	var legend cell
+	for x, driver := range drivers {
+		marker := " "
+		if !html && x == s.chartCursor {
+			marker = ">"
+		}
+		name := driver.ShortName
+		if driver.Number == s.chartReference {
+			name = "*" + name
+		}
+
+		color := "#808080"
+		if s.chartDrivers[driver.Number] {
+			color = driver.HexColor
+		}
+		legend = append(legend, span{text: marker}, span{text: name, color: color})
+	}
+
+	if html {
+		return title + "\n" + gapChartSVG(series) + "\n" + renderSpans(legend, true)
+	}
+
+	width := 100
+	if s.currentWidth > 0 {
+		width = s.currentWidth
+	}
+	// Leave room for the title, axis, legend and help lines
+	height := 20
+	if s.currentHeight > 0 {
+		height = max(4, s.currentHeight-5)
+	}
+
+	lines := []string{title}
+	lines = append(lines, gapChartLines(series, width, height)...)
+	lines = append(lines, renderSpans(legend, false))
+	lines = append(lines, "Left/Right: choose driver, Space: show/hide, Enter: set reference (*), Esc: back to the timing tower")
+
+	return strings.Join(lines, "\n")
+}
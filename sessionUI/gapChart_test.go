@@ -0,0 +1,42 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"testing"
+)
+
+func TestGapChart(t *testing.T) {
+	session, web := playRace(t, lapsScript(), NewSettings())
+	session.Resize(tea.WindowSizeMsg{Width: 60, Height: 14})
+
+	if page, _ := session.Update(keyMsg("g")); page != ui.GapChart {
+		t.Fatalf("expected the gap chart, got %v", page)
+	}
+	checkGolden(t, "gap_chart", session.View())
+	checkGolden(t, "gap_chart_html", web.String())
+
+	// Gaps to HAM instead of the leader
+	session.Update(tea.KeyMsg{Type: tea.KeyRight})
+	session.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	checkGolden(t, "gap_chart_reference", session.View())
+
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.Replay {
+		t.Fatalf("expected escape to return to the tower, got %v", page)
+	}
+}
@@ -0,0 +1,76 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+)
+
+// gridCell is the number of places gained since the start, blank if the start wasn't seen
+func (s *sessionBase) gridCell(driver Messages.Timing) cell {
+	grid, exists := s.positions.Grid(driver.Number)
+	if !exists || driver.Position <= 0 {
+		return nil
+	}
+
+	gained := grid - driver.Position
+	switch {
+	case gained > 0:
+		return text(fmt.Sprintf("+%d", gained), "#00FF00")
+	case gained < 0:
+		return text(fmt.Sprintf("%d", gained), "#FF0000")
+	default:
+		return text("0", "")
+	}
+}
+
+// overtakeMessage describes a change in the feed. Only passes on track and places lost in the pits or by stopping
+// are included, the other side of each of those is left out so every change is only listed once.
+func overtakeMessage(change history.PositionChange, drivers map[int]Messages.Timing) (cell, bool) {
+	name := drivers[change.Driver].ShortName
+	other := drivers[change.Other].ShortName
+
+	switch {
+	case change.Reason == history.OnTrack && change.Gained() && change.Other != 0:
+		return text(fmt.Sprintf("%s passed %s for P%d", name, other, change.To), "#00FF00"), true
+	case change.Reason != history.OnTrack && !change.Gained():
+		reason := "in the pits"
+		if change.Reason == history.Retirement {
+			reason = "after stopping"
+		}
+		return text(fmt.Sprintf("%s dropped from P%d to P%d %s", name, change.From, change.To, reason), "#FFFF00"), true
+	default:
+		return nil, false
+	}
+}
+
+// overtakeLines is the most recent changes for the overtake feed, newest first
+func (s *sessionBase) overtakeLines(count int, drivers map[int]Messages.Timing, html bool) []string {
+	var lines []string
+	changes := s.positions.Changes()
+	for x := len(changes) - 1; x >= 0 && len(lines) < count; x-- {
+		message, include := overtakeMessage(changes[x], drivers)
+		if !include {
+			continue
+		}
+
+		prefix := fmt.Sprintf("%s - Lap %d - ", changes[x].Timestamp.In(s.f.CircuitTimezone()).Format("15:04:05"), changes[x].Lap)
+		lines = append(lines, prefix+renderSpans(message, html))
+	}
+	return lines
+}
@@ -0,0 +1,72 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"f1gopher/f1gopher-cmdline/history"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+	"time"
+)
+
+func TestOvertakes(t *testing.T) {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+	data.Add(testEvent(Messages.Race, Messages.Started))
+
+	first := testDriver(1, 1, "VER", "#3671C6")
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	third := testDriver(3, 16, "LEC", "#F91536")
+	for _, driver := range []*Messages.Timing{&first, &second, &third} {
+		driver.Lap = 1
+	}
+	data.Add(first, second, third)
+
+	// HAM passes VER on track
+	second.Lap = 3
+	second.Position = 1
+	second.Timestamp = sessionStart.Add(4 * time.Minute)
+	first.Lap = 3
+	first.Position = 2
+	first.Timestamp = second.Timestamp
+	data.Add(Messages.EventTime{Timestamp: second.Timestamp, Remaining: time.Hour}, second, first)
+
+	// Then VER drops behind LEC in the pits
+	first.Lap = 5
+	first.Position = 3
+	first.Location = Messages.Pitlane
+	first.Timestamp = sessionStart.Add(7 * time.Minute)
+	third.Lap = 5
+	third.Position = 2
+	third.Timestamp = first.Timestamp
+	data.Add(Messages.EventTime{Timestamp: first.Timestamp, Remaining: time.Hour}, first, third,
+		Messages.RaceControlMessage{Timestamp: first.Timestamp, Msg: "CAR 1 (VER) PIT LANE SPEEDING"})
+
+	session, _ := playRace(t, data, raceColumns("Pos", "Driver", "Grid +/-", "Gap"))
+
+	changes := session.positions.Changes()
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 position changes, got %d", len(changes))
+	}
+	if changes[0].Driver != 44 || changes[0].Other != 1 || changes[0].Reason != history.OnTrack {
+		t.Errorf("unexpected first change: %+v", changes[0])
+	}
+	if changes[2].Driver != 1 || changes[2].Other != 16 || changes[2].Reason != history.PitStop {
+		t.Errorf("unexpected third change: %+v", changes[2])
+	}
+
+	checkGolden(t, "overtakes", session.View())
+}
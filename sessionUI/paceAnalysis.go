@@ -0,0 +1,149 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"time"
+)
+
+// fmtDegradation is the change in lap time per lap of tire age in seconds
+func fmtDegradation(d time.Duration) string {
+	return fmt.Sprintf("%+.3f", d.Seconds())
+}
+
+// degradationCell is blank when there weren't enough clean laps to work out the degradation
+func degradationCell(degradation time.Duration, hasDegradation bool) cell {
+	if !hasDegradation {
+		return nil
+	}
+	return text(fmtDegradation(degradation), deltaColor(degradation))
+}
+
+// currentPace is the pace of the stint the driver is on, false if there are no clean laps on the current tires
+func (s *sessionBase) currentPace(driver Messages.Timing) (history.StintPace, bool) {
+	laps := s.history.Laps(driver.Number)
+	if len(laps) == 0 {
+		return history.StintPace{}, false
+	}
+
+	// Changed tires since the last completed lap
+	lastLap := laps[len(laps)-1]
+	if driver.Tire != lastLap.Tire || driver.LapsOnTire < lastLap.LapsOnTire || driver.Pitstops > lastLap.Pitstops {
+		return history.StintPace{}, false
+	}
+
+	paces := history.Pace(laps)
+	current := paces[len(paces)-1]
+	return current, current.CleanLaps > 0
+}
+
+// currentPaces is the current stint pace for each driver with clean laps on their current tires
+func (s *sessionBase) currentPaces() map[int]history.StintPace {
+	result := make(map[int]history.StintPace)
+	for _, driver := range s.sortedDrivers() {
+		if pace, exists := s.currentPace(driver); exists {
+			result[driver.Number] = pace
+		}
+	}
+	return result
+}
+
+// paceAnalysisView displays the pace and degradation of every stint and compound for the terminal or web server
+func (s *sessionBase) paceAnalysisView(html bool) string {
+	drivers := s.sortedDrivers()
+	paces := s.history.Paces()
+
+	stintColumns := []layoutColumn{
+		{column: &column{header: "Pos"}, width: 5},
+		{column: &column{header: "Driver"}, width: 8},
+		{column: &column{header: "Tire"}, width: 10},
+		{column: &column{header: "Laps"}, width: 9},
+		{column: &column{header: "Clean"}, width: 7},
+		{column: &column{header: "Average"}, width: timeWidth},
+		{column: &column{header: "Deg/Lap"}, width: 9},
+	}
+	separator := columnSeparator(stintColumns)
+
+	lines := []string{
+		"Pace Analysis",
+		fmt.Sprintf("Excludes the first lap, pit laps, safety car and yellow flag laps and laps within %.1fs of the car in front",
+			history.TrafficGap.Seconds()),
+		s.renderHeader(stintColumns),
+		separator,
+	}
+
+	for _, driver := range drivers {
+		for x, stint := range paces[driver.Number] {
+			position := text(fmt.Sprintf("%d", driver.Position), "")
+			name := text(driver.ShortName, driver.HexColor)
+			if x > 0 {
+				position = nil
+				name = nil
+			}
+
+			var average cell
+			if stint.CleanLaps > 0 {
+				average = text(fmtDuration(stint.Average), "")
+			}
+
+			lines = append(lines, renderCells(stintColumns, []cell{
+				position,
+				name,
+				text(stint.Tire.String(), tireColor(stint.Tire)),
+				text(fmt.Sprintf("%d-%d", stint.StartLap, stint.EndLap), ""),
+				text(fmt.Sprintf("%d", stint.CleanLaps), ""),
+				average,
+				degradationCell(stint.Degradation, stint.HasDegradation),
+			}, html))
+		}
+	}
+	lines = append(lines, separator)
+
+	compoundColumns := []layoutColumn{
+		{column: &column{header: "Compound"}, width: 10},
+		{column: &column{header: "Drivers"}, width: 9},
+		{column: &column{header: "Clean Laps"}, width: 12},
+		{column: &column{header: "Average"}, width: timeWidth},
+		{column: &column{header: "Deg/Lap"}, width: 9},
+	}
+	lines = append(lines, "", s.renderHeader(compoundColumns), columnSeparator(compoundColumns))
+
+	compounds := history.CompoundPaces(paces)
+	for _, compound := range compounds {
+		lines = append(lines, renderCells(compoundColumns, []cell{
+			text(compound.Tire.String(), tireColor(compound.Tire)),
+			text(fmt.Sprintf("%d", compound.Drivers), ""),
+			text(fmt.Sprintf("%d", compound.CleanLaps), ""),
+			text(fmtDuration(compound.Average), ""),
+			degradationCell(compound.Degradation, compound.HasDegradation),
+		}, html))
+	}
+	if len(compounds) == 0 {
+		lines = append(lines, "No clean laps")
+	}
+	lines = append(lines, columnSeparator(compoundColumns))
+
+	if !html {
+		lines = s.scrollPage(lines, 4)
+		lines = append(lines, "Up/Down: scroll, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
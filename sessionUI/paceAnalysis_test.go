@@ -0,0 +1,36 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/ui"
+	"strings"
+	"testing"
+)
+
+func TestPaceAnalysis(t *testing.T) {
+	session, _ := playRace(t, lapsScript(), raceColumns("Pos", "Driver", "Pace"))
+
+	// HAM has been close behind VER the whole time so only VER has any clean laps
+	if tower := session.View(); !strings.Contains(tower, "01:31.000 +0.000") {
+		t.Errorf("expected the pace for VER in the tower:\n%s", tower)
+	}
+
+	if page, _ := session.Update(keyMsg("a")); page != ui.PaceAnalysis {
+		t.Fatalf("expected the pace analysis, got %v", page)
+	}
+	checkGolden(t, "pace_analysis", session.View())
+}
@@ -0,0 +1,231 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/json"
+	"errors"
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const defaultUndercutRange = 3.0
+
+// PitLaneLosses is the time lost making a pit stop at each circuit in seconds, circuits that aren't listed use the
+// time from the session data. Cars closer than the undercut range to the car in front are checked for undercuts.
+type PitLaneLosses struct {
+	UndercutRange float64            `json:"undercutRange"`
+	Circuits      map[string]float64 `json:"circuits"`
+}
+
+func NewPitLaneLosses() *PitLaneLosses {
+	return &PitLaneLosses{
+		UndercutRange: defaultUndercutRange,
+		Circuits:      map[string]float64{},
+	}
+}
+
+// LoadPitLaneLosses reads the pit lane losses from path, using the defaults if the file doesn't exist
+func LoadPitLaneLosses(path string) (*PitLaneLosses, error) {
+	losses := NewPitLaneLosses()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return losses, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, losses); err != nil {
+		return nil, fmt.Errorf("invalid pit lane loss file %s: %v", path, err)
+	}
+
+	if losses.UndercutRange < 0 {
+		return nil, fmt.Errorf("invalid pit lane loss file %s: negative undercut range", path)
+	}
+	for circuit, loss := range losses.Circuits {
+		if loss <= 0 {
+			return nil, fmt.Errorf("invalid pit lane loss file %s: circuit \"%s\" has no time lost", path, circuit)
+		}
+	}
+
+	return losses, nil
+}
+
+// Loss returns the time lost in the pit lane at a circuit or the fallback if the circuit isn't configured
+func (p *PitLaneLosses) Loss(circuit string, fallback time.Duration) time.Duration {
+	for name, loss := range p.Circuits {
+		if strings.EqualFold(name, circuit) {
+			return time.Duration(loss * float64(time.Second))
+		}
+	}
+	return fallback
+}
+
+// pitProjection is where a driver would rejoin if they pitted now
+type pitProjection struct {
+	position int
+	// The cars they would rejoin behind and in front of and the gaps to them, nil at either end of the field
+	ahead     *Messages.Timing
+	gapAhead  time.Duration
+	behind    *Messages.Timing
+	gapBehind time.Duration
+
+	// Close enough to the car in front to pit first and come out in clear air
+	canUndercut bool
+	// Close enough to the car in front but pitting first would rejoin in traffic so staying out is better
+	canOvercut bool
+	// The car behind can undercut this driver
+	undercutThreat bool
+}
+
+// canPit is false for drivers that are already in the pits or have stopped
+func canPit(driver Messages.Timing) bool {
+	switch driver.Location {
+	case Messages.Pitlane, Messages.PitOut, Messages.Stopped, Messages.OutOfRace:
+		return false
+	default:
+		return true
+	}
+}
+
+// projectPitStops works out where each driver would rejoin if they pitted now from the current gaps to the leader.
+// Lapped cars have no gap to the leader so are left out, they are behind anywhere a car on the lead lap would rejoin.
+func projectPitStops(drivers []Messages.Timing, loss time.Duration, undercutRange time.Duration) map[int]pitProjection {
+	// Everyone still running on the lead lap in race order
+	var running []Messages.Timing
+	for _, driver := range drivers {
+		if _, exists := lapGap(driver.Position, driver.GapToLeader); !exists {
+			continue
+		}
+		if driver.Location != Messages.Stopped && driver.Location != Messages.OutOfRace {
+			running = append(running, driver)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].Position < running[j].Position
+	})
+
+	result := make(map[int]pitProjection)
+	for _, driver := range running {
+		if !canPit(driver) {
+			continue
+		}
+
+		rejoin := driver.GapToLeader + loss
+		projection := pitProjection{position: 1}
+		for x := range running {
+			other := running[x]
+			if other.Number == driver.Number {
+				continue
+			}
+
+			if other.GapToLeader < rejoin {
+				projection.position++
+				projection.ahead = &running[x]
+				projection.gapAhead = rejoin - other.GapToLeader
+			} else if projection.behind == nil {
+				projection.behind = &running[x]
+				projection.gapBehind = other.GapToLeader - rejoin
+			}
+		}
+
+		result[driver.Number] = projection
+	}
+
+	// Compare each pair of cars next to each other on track
+	for x := 1; x < len(running); x++ {
+		ahead := running[x-1]
+		behind := running[x]
+		projection, exists := result[behind.Number]
+		if !exists || behind.TimeDiffToPositionAhead <= 0 || behind.TimeDiffToPositionAhead > undercutRange {
+			continue
+		}
+
+		if projection.ahead == nil || projection.gapAhead >= history.TrafficGap {
+			projection.canUndercut = true
+
+			if threatened, exists := result[ahead.Number]; exists {
+				threatened.undercutThreat = true
+				result[ahead.Number] = threatened
+			}
+		} else {
+			projection.canOvercut = true
+		}
+		result[behind.Number] = projection
+	}
+
+	return result
+}
+
+// pitLoss is the time lost making a pit stop at the current circuit
+func (s *sessionBase) pitLoss() time.Duration {
+	if s.pitLosses != nil {
+		return s.pitLosses.Loss(s.f.Track(), s.f.TimeLostInPitlane())
+	}
+	return s.f.TimeLostInPitlane()
+}
+
+// pitProjections returns where each driver would rejoin using the pit lane loss for the current circuit
+func (s *sessionBase) pitProjections() map[int]pitProjection {
+	undercutRange := time.Duration(defaultUndercutRange * float64(time.Second))
+	if s.pitLosses != nil {
+		undercutRange = time.Duration(s.pitLosses.UndercutRange * float64(time.Second))
+	}
+
+	return projectPitStops(s.sortedDrivers(), s.pitLoss(), undercutRange)
+}
+
+// pitRejoinCell is the rejoin position and the cars either side with the gaps to them. Red when they would rejoin in
+// traffic. U marks a possible undercut on the car in front, O an overcut and T a threat from the car behind.
+func pitRejoinCell(projection pitProjection, compact bool) cell {
+	color := "#00FF00"
+	if projection.ahead != nil && projection.gapAhead < history.TrafficGap {
+		color = "#FF0000"
+	}
+
+	result := cell{{text: fmt.Sprintf("P%d", projection.position), color: color}}
+	if !compact {
+		if projection.ahead != nil {
+			result = append(result, span{text: fmt.Sprintf(" %s-%.1f", projection.ahead.ShortName, projection.gapAhead.Seconds())})
+		}
+		if projection.behind != nil {
+			result = append(result, span{text: fmt.Sprintf(" %s+%.1f", projection.behind.ShortName, projection.gapBehind.Seconds())})
+		}
+	}
+
+	markers := ""
+	if projection.canUndercut {
+		markers += "U"
+	}
+	if projection.canOvercut {
+		markers += "O"
+	}
+	if projection.undercutThreat {
+		markers += "T"
+	}
+	if len(markers) > 0 {
+		result = append(result, span{text: " " + markers, color: "#FFFF00"})
+	}
+
+	return result
+}
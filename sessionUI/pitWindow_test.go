@@ -0,0 +1,96 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadPitLaneLosses(t *testing.T) {
+	dir := t.TempDir()
+
+	losses, err := LoadPitLaneLosses(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if losses.UndercutRange != defaultUndercutRange || losses.Loss("Silverstone", 20*time.Second) != 20*time.Second {
+		t.Errorf("expected the defaults, got %+v", losses)
+	}
+
+	path := filepath.Join(dir, "pitloss.json")
+	os.WriteFile(path, []byte(`{"circuits":{"silverstone":22.5}}`), 0644)
+	losses, err = LoadPitLaneLosses(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if losses.Loss("Silverstone", 20*time.Second) != 22500*time.Millisecond || losses.Loss("Monza", 20*time.Second) != 20*time.Second {
+		t.Errorf("unexpected losses: %+v", losses)
+	}
+
+	os.WriteFile(path, []byte(`{"circuits":{"Monza":-1}}`), 0644)
+	if _, err = LoadPitLaneLosses(path); err == nil {
+		t.Error("expected an error for a negative loss")
+	}
+}
+
+func TestProjectPitStops(t *testing.T) {
+	driver := func(position int, number int, gap time.Duration, interval time.Duration) Messages.Timing {
+		return Messages.Timing{Position: position, Number: number, ShortName: fmt.Sprintf("D%d", number),
+			GapToLeader: gap, TimeDiffToPositionAhead: interval, Location: Messages.OnTrack}
+	}
+
+	drivers := []Messages.Timing{
+		driver(1, 1, 0, 0),
+		driver(2, 2, 2*time.Second, 2*time.Second),
+		driver(3, 3, 10*time.Second, 8*time.Second),
+		driver(4, 4, 21500*time.Millisecond, 11500*time.Millisecond),
+		driver(5, 5, 0, 0),
+		driver(6, 6, 0, 0),
+	}
+	drivers[4].Location = Messages.Stopped
+
+	// Pitting now the second car would come out half a second behind the fourth so is better staying out
+	projections := projectPitStops(drivers, 20*time.Second, 3*time.Second)
+	leader := projections[1]
+	if leader.position != 3 || leader.ahead.Number != 3 || leader.gapAhead != 10*time.Second ||
+		leader.behind.Number != 4 || leader.gapBehind != 1500*time.Millisecond {
+		t.Errorf("unexpected projection for the leader: %+v", leader)
+	}
+	if second := projections[2]; second.position != 4 || !second.canOvercut || second.canUndercut || leader.undercutThreat {
+		t.Errorf("expected an overcut for the second car: %+v", second)
+	}
+	if _, exists := projections[5]; exists {
+		t.Error("expected no projection for a stopped car")
+	}
+	if _, exists := projections[6]; exists {
+		t.Error("expected no projection for a lapped car")
+	}
+
+	// With a longer pit lane they come out in clear air
+	projections = projectPitStops(drivers, 25*time.Second, 3*time.Second)
+	if !projections[2].canUndercut || !projections[1].undercutThreat || projections[3].canUndercut {
+		t.Errorf("expected an undercut for the second car: %+v", projections)
+	}
+
+	if value := renderSpans(pitRejoinCell(projections[2], false), false); value != "P4 D4-5.5 U" {
+		t.Errorf("unexpected rejoin display: %s", value)
+	}
+}
@@ -0,0 +1,176 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// How often the replay speed is applied, the same as the replay updates the session time
+const playbackTick = 500 * time.Millisecond
+
+// PlaybackSpeed is how fast a replay plays compared to real time
+type PlaybackSpeed int
+
+const (
+	HalfSpeed PlaybackSpeed = iota
+	NormalSpeed
+	DoubleSpeed
+	QuadrupleSpeed
+	TenTimesSpeed
+	// As fast as the data can be read, can only be chosen when the replay starts
+	MaxSpeed
+)
+
+func (p PlaybackSpeed) String() string {
+	switch p {
+	case HalfSpeed:
+		return "0.5x"
+	case NormalSpeed:
+		return "1x"
+	case DoubleSpeed:
+		return "2x"
+	case QuadrupleSpeed:
+		return "4x"
+	case TenTimesSpeed:
+		return "10x"
+	case MaxSpeed:
+		return "Max"
+	default:
+		panic("Unhandled playback speed")
+	}
+}
+
+// ParsePlaybackSpeed reads a speed of 0.5, 1, 2, 4 or 10 with or without a trailing x, or max
+func ParsePlaybackSpeed(value string) (PlaybackSpeed, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	for speed := HalfSpeed; speed <= MaxSpeed; speed++ {
+		name := strings.ToLower(speed.String())
+		if value == name || value == strings.TrimSuffix(name, "x") {
+			return speed, nil
+		}
+	}
+	return NormalSpeed, fmt.Errorf("unknown playback speed \"%s\", use 0.5, 1, 2, 4, 10 or max", value)
+}
+
+// skip is how far ahead to move the replay each tick on top of the time that has passed
+func (p PlaybackSpeed) skip() time.Duration {
+	switch p {
+	case DoubleSpeed:
+		return playbackTick
+	case QuadrupleSpeed:
+		return 3 * playbackTick
+	case TenTimesSpeed:
+		return 9 * playbackTick
+	default:
+		return 0
+	}
+}
+
+// playbackSpeed is the current replay speed
+func (s *sessionBase) playbackSpeed() PlaybackSpeed {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	return s.speed
+}
+
+// changeSpeed moves up or down the speeds that can be changed to during a replay. Live sessions and replays started
+// at max speed can't be changed.
+func (s *sessionBase) changeSpeed(faster bool) {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	if s.isLive || s.speed == MaxSpeed {
+		return
+	}
+
+	if faster {
+		s.speed = min(TenTimesSpeed, s.speed+1)
+	} else {
+		s.speed = max(HalfSpeed, s.speed-1)
+	}
+}
+
+// togglePause pauses or resumes the session, a replay stopped for half speed playback stays stopped when paused.
+// The replay doesn't move while paused so during a jump it is paused once the jump has finished.
+func (s *sessionBase) togglePause() {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	if s.seeking.Load() {
+		s.pauseAfterSeek = !s.pauseAfterSeek
+		return
+	}
+	s.switchPause()
+}
+
+// switchPause is togglePause for when playbackLock is held
+func (s *sessionBase) switchPause() {
+	s.paused = !s.paused
+	if s.halted {
+		s.halted = false
+		return
+	}
+	s.f.TogglePause()
+}
+
+// isPaused is true when the session has been paused, not when a replay is briefly stopped for half speed playback
+func (s *sessionBase) isPaused() bool {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	return s.f.IsPaused() && !s.halted
+}
+
+// playAtSpeed keeps a replay at the chosen speed until the session is left
+func (s *sessionBase) playAtSpeed() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(playbackTick)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.playbackStep()
+		}
+	}
+}
+
+// playbackStep skips the replay ahead for faster speeds or stops it every other tick for half speed
+func (s *sessionBase) playbackStep() {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	if s.paused {
+		return
+	}
+
+	// Also restarts the replay if it was left stopped when the speed changed
+	if s.halted || s.speed == HalfSpeed {
+		s.halted = !s.halted
+		s.f.TogglePause()
+	}
+
+	if skip := s.speed.skip(); skip > 0 {
+		s.f.IncrementTime(skip)
+	}
+}
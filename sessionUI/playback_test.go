@@ -0,0 +1,70 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPlaybackSpeed(t *testing.T) {
+	for value, expected := range map[string]PlaybackSpeed{"0.5": HalfSpeed, "2x": DoubleSpeed, "10": TenTimesSpeed, "MAX": MaxSpeed} {
+		if speed, err := ParsePlaybackSpeed(value); err != nil || speed != expected {
+			t.Errorf("expected %s for %s, got %s %v", expected, value, speed, err)
+		}
+	}
+	if _, err := ParsePlaybackSpeed("3"); err == nil {
+		t.Error("expected an error for an unknown speed")
+	}
+
+	// Step the playback by hand rather than entering the session so the ticks are predictable
+	data := raceScript()
+	settings := NewSettings()
+	settings.Speed = DoubleSpeed
+	session := NewRaceUI(&recordedHTML{}, settings)
+	session.f = data
+	session.speed = session.launchSpeed
+
+	session.playbackStep()
+	session.Update(keyMsg("+"))
+	session.playbackStep()
+	if increments := data.TimeIncrements(); len(increments) != 2 || increments[0] != 500*time.Millisecond || increments[1] != 1500*time.Millisecond {
+		t.Errorf("unexpected time increments: %v", increments)
+	}
+
+	// Half speed stops the replay every other tick without showing it as paused
+	for x := 0; x < 3; x++ {
+		session.Update(keyMsg("-"))
+	}
+	if session.playbackSpeed() != HalfSpeed {
+		t.Fatalf("expected half speed, got %s", session.playbackSpeed())
+	}
+	session.playbackStep()
+	if !data.IsPaused() || session.isPaused() {
+		t.Error("expected the replay to be stopped but not paused")
+	}
+
+	// Pausing while stopped keeps it stopped until it is resumed
+	session.control(pauseControl)
+	session.playbackStep()
+	if !data.IsPaused() || !session.isPaused() {
+		t.Error("expected the replay to be paused")
+	}
+	session.control(pauseControl)
+	if data.IsPaused() || session.isPaused() {
+		t.Error("expected the replay to be resumed")
+	}
+}
@@ -0,0 +1,136 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sort"
+	"time"
+)
+
+// Number of recent clean laps used for the pace of each driver in the projection
+const projectionLaps = 5
+
+// projectFinish forecasts the finishing order from the current gaps to the leader, the pace of each driver over the
+// remaining laps and the time lost for any stops they still have to make. The drivers are returned in projected order
+// with their position and gaps replaced by the projected ones at the flag. Drivers without a pace use the average of
+// everyone else. Lapped drivers have no gap to the leader so keep their order behind the lead lap with no gaps, and
+// drivers who have stopped stay at the back.
+func projectFinish(drivers []Messages.Timing, paces map[int]time.Duration, pendingStops map[int]bool,
+	remainingLaps int, loss time.Duration) []Messages.Timing {
+
+	var average time.Duration
+	if len(paces) > 0 {
+		for _, pace := range paces {
+			average += pace
+		}
+		average /= time.Duration(len(paces))
+	}
+
+	var running []Messages.Timing
+	var lapped []Messages.Timing
+	var stopped []Messages.Timing
+	finish := make(map[int]time.Duration, len(drivers))
+	for _, driver := range drivers {
+		if driver.Location == Messages.Stopped || driver.Location == Messages.OutOfRace {
+			stopped = append(stopped, driver)
+			continue
+		}
+		if _, exists := lapGap(driver.Position, driver.GapToLeader); !exists {
+			lapped = append(lapped, driver)
+			continue
+		}
+
+		pace, exists := paces[driver.Number]
+		if !exists {
+			pace = average
+		}
+
+		total := driver.GapToLeader + time.Duration(remainingLaps)*pace
+		if pendingStops[driver.Number] {
+			total += loss
+		}
+		finish[driver.Number] = total
+		running = append(running, driver)
+	}
+
+	sort.SliceStable(running, func(i, j int) bool {
+		if finish[running[i].Number] == finish[running[j].Number] {
+			return running[i].Position < running[j].Position
+		}
+		return finish[running[i].Number] < finish[running[j].Number]
+	})
+	sort.SliceStable(lapped, func(i, j int) bool {
+		return lapped[i].Position < lapped[j].Position
+	})
+	sort.SliceStable(stopped, func(i, j int) bool {
+		return stopped[i].Position < stopped[j].Position
+	})
+
+	result := make([]Messages.Timing, 0, len(drivers))
+	for x, driver := range running {
+		driver.Position = x + 1
+		driver.GapToLeader = finish[driver.Number] - finish[running[0].Number]
+		driver.TimeDiffToPositionAhead = 0
+		if x > 0 {
+			driver.TimeDiffToPositionAhead = finish[driver.Number] - finish[running[x-1].Number]
+		}
+		result = append(result, driver)
+	}
+	for _, driver := range append(lapped, stopped...) {
+		driver.Position = len(result) + 1
+		driver.GapToLeader = 0
+		driver.TimeDiffToPositionAhead = 0
+		result = append(result, driver)
+	}
+
+	return result
+}
+
+// remainingLaps is the number of laps left after the one the leader is on
+func (s *sessionBase) remainingLaps() int {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	return max(0, s.event.TotalLaps-s.event.CurrentLap)
+}
+
+// tower is the order of the timing tower, the projected finishing order when the projection is shown
+func (s *sessionBase) tower(drivers []Messages.Timing) []Messages.Timing {
+	if !s.projecting || !s.isRace() {
+		return drivers
+	}
+
+	// Sprints don't have to use two compounds so nobody has to stop
+	pendingStops := make(map[int]bool, len(drivers))
+	if s.f.Session() == Messages.RaceSession {
+		for _, driver := range drivers {
+			pendingStops[driver.Number] = history.PendingStop(s.history.CurrentStints(driver))
+		}
+	}
+
+	return projectFinish(drivers, s.history.RecentPaces(projectionLaps), pendingStops, s.remainingLaps(), s.pitLoss())
+}
+
+// projectionLabel marks the title while the projection is shown so it isn't mistaken for the live order
+func (s *sessionBase) projectionLabel(html bool) string {
+	if !s.projecting || !s.isRace() {
+		return ""
+	}
+	return renderSpans(text(fmt.Sprintf("PROJECTED FINISH (%d laps to go)", s.remainingLaps()), "#FFFF00"), html) + " - "
+}
@@ -0,0 +1,128 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+	"time"
+)
+
+// projectionScript is lap 40 of 52 with VER leading but slower than HAM and both still to stop, LEC has already
+// changed tires
+func projectionScript(session Messages.SessionType) *fakeSession.Session {
+	data := fakeSession.New(session, "Fake Grand Prix", sessionStart)
+	data.SetTimeLostInPitlane(20 * time.Second)
+	event := testEvent(Messages.Race, Messages.Started)
+	event.CurrentLap = 40
+	data.Add(event)
+
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	second.GapToLeader = 1500 * time.Millisecond
+	second.TimeDiffToPositionAhead = 1500 * time.Millisecond
+	third := testDriver(3, 16, "LEC", "#F91536")
+	third.GapToLeader = 15 * time.Second
+	third.TimeDiffToPositionAhead = 13500 * time.Millisecond
+	for lap := 35; lap <= 39; lap++ {
+		for _, driver := range []*Messages.Timing{&leader, &second, &third} {
+			driver.Lap = lap
+			driver.Timestamp = sessionStart.Add(time.Duration(lap) * 90 * time.Second)
+		}
+		leader.LastLap = 91 * time.Second
+		second.LastLap = 90500 * time.Millisecond
+		third.LastLap = 90900 * time.Millisecond
+		third.Tire = Messages.Hard
+		if lap == 35 {
+			third.Tire = Messages.Medium
+		}
+		data.Add(Messages.EventTime{Timestamp: leader.Timestamp, Remaining: time.Hour}, leader, second, third)
+	}
+
+	return data
+}
+
+func TestProjection(t *testing.T) {
+	session, _ := playRace(t, projectionScript(Messages.RaceSession), raceColumns("Pos", "Driver", "Gap", "Interval", "Tire"))
+	session.Update(keyMsg("t"))
+	session.Update(keyMsg("f"))
+	checkGolden(t, "projection", session.View())
+
+	// Toggling again goes back to the live order
+	session.Update(keyMsg("f"))
+	if session.projecting {
+		t.Error("expected the projection to be hidden")
+	}
+}
+
+func TestProjectionSprint(t *testing.T) {
+	session, _ := playRace(t, projectionScript(Messages.SprintSession), NewSettings())
+	session.Update(keyMsg("f"))
+
+	// Without stops to make the faster HAM is projected to pass VER and LEC stays behind
+	var order []int
+	for _, driver := range session.tower(session.sortedDrivers()) {
+		order = append(order, driver.Number)
+	}
+	if len(order) != 3 || order[0] != 44 || order[1] != 1 || order[2] != 16 {
+		t.Errorf("unexpected projected order: %v", order)
+	}
+}
+
+func TestProjectFinish(t *testing.T) {
+	driver := func(position int, number int, gap time.Duration) Messages.Timing {
+		return Messages.Timing{Position: position, Number: number, GapToLeader: gap, Location: Messages.OnTrack}
+	}
+
+	drivers := []Messages.Timing{
+		driver(1, 1, 0),
+		driver(2, 2, 1500*time.Millisecond),
+		driver(3, 3, 15*time.Second),
+		driver(4, 4, 20*time.Second),
+		driver(5, 5, 0),
+		driver(6, 6, 0),
+	}
+	drivers[4].Location = Messages.Stopped
+
+	// The third car has already stopped, the fourth has no pace so uses the average of the others and the sixth is
+	// lapped so stays behind the lead lap
+	paces := map[int]time.Duration{1: 91 * time.Second, 2: 90500 * time.Millisecond, 3: 90900 * time.Millisecond}
+	pending := map[int]bool{1: true, 2: true, 4: true}
+	projected := projectFinish(drivers, paces, pending, 12, 20*time.Second)
+
+	expected := []struct {
+		number   int
+		gap      time.Duration
+		interval time.Duration
+	}{
+		{3, 0, 0},
+		{2, 1700 * time.Millisecond, 1700 * time.Millisecond},
+		{1, 6200 * time.Millisecond, 4500 * time.Millisecond},
+		{4, 23800 * time.Millisecond, 17600 * time.Millisecond},
+		{6, 0, 0},
+		{5, 0, 0},
+	}
+	if len(projected) != len(expected) {
+		t.Fatalf("expected %d drivers, got %d", len(expected), len(projected))
+	}
+	for x, e := range expected {
+		p := projected[x]
+		if p.Number != e.number || p.Position != x+1 || p.GapToLeader != e.gap || p.TimeDiffToPositionAhead != e.interval {
+			t.Errorf("unexpected projection for P%d: %+v", x+1, p)
+		}
+	}
+}
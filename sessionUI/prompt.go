@@ -0,0 +1,99 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"fmt"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptKind is what the text typed into the status line is for
+type promptKind int
+
+const (
+	noPrompt promptKind = iota
+	seekPrompt
+	bookmarkPrompt
+)
+
+// openPrompt replaces the status line with a prompt to type into
+func (s *sessionBase) openPrompt(kind promptKind) {
+	s.prompt = kind
+	s.promptText = ""
+	s.promptError = ""
+}
+
+// updatePrompt handles the keys while a prompt is open
+func (s *sessionBase) updatePrompt(msg tea.KeyMsg) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		s.prompt = noPrompt
+		return
+
+	case tea.KeyEnter:
+		var err error
+		switch s.prompt {
+		case seekPrompt:
+			err = s.submitSeek(s.promptText)
+		case bookmarkPrompt:
+			err = s.addBookmark(s.promptText)
+		default:
+			panic("Unhandled prompt")
+		}
+
+		// Stay open so the text can be corrected
+		if err != nil {
+			s.promptError = err.Error()
+			return
+		}
+		s.prompt = noPrompt
+		return
+
+	case tea.KeyBackspace:
+		if len(s.promptText) > 0 {
+			runes := []rune(s.promptText)
+			s.promptText = string(runes[:len(runes)-1])
+		}
+
+	case tea.KeyRunes, tea.KeySpace:
+		s.promptText += string(msg.Runes)
+	}
+
+	s.promptError = ""
+}
+
+// promptLine replaces the status line while a prompt is open
+func (s *sessionBase) promptLine() string {
+	var line string
+	switch s.prompt {
+	case seekPrompt:
+		line = "Jump to track time (e.g. 14:35:00), Enter: go, Esc: cancel: "
+		if s.isRace() {
+			line = "Jump to lap (e.g. 23) or track time (e.g. 14:35:00), Enter: go, Esc: cancel: "
+		}
+	case bookmarkPrompt:
+		line = fmt.Sprintf("Bookmark lap %d at %s, note (optional), Enter: save, Esc: cancel: ",
+			s.pendingBookmark.Lap, s.pendingBookmark.Time.In(s.f.CircuitTimezone()).Format("15:04:05"))
+	default:
+		panic("Unhandled prompt")
+	}
+
+	line += s.promptText + "_"
+	if len(s.promptError) > 0 {
+		line += renderSpans(text(" "+s.promptError, "#FF0000"), false)
+	}
+	return line
+}
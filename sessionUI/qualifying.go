@@ -0,0 +1,245 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"sort"
+	"time"
+)
+
+type qualifyingFormat int
+
+const (
+	unknownFormat qualifyingFormat = iota
+	standardFormat
+	sprintFormat
+)
+
+// Length of SQ1, SQ2 and SQ3, standard qualifying is 18, 15 and 12 minutes
+var sprintLengths = [3]time.Duration{12 * time.Minute, 10 * time.Minute, 8 * time.Minute}
+
+// How close the clock has to be to the length of a part to match the format
+const partLengthTolerance = 30 * time.Second
+
+// Laps completed within this time are used to work out how much faster the track is getting
+const trackEvolutionWindow = 10 * time.Minute
+
+// qualifyingPart is 1, 2 or 3 for the parts of qualifying and 0 for any other type of event
+func qualifyingPart(eventType Messages.EventType) int {
+	switch eventType {
+	case Messages.Qualifying1:
+		return 1
+	case Messages.Qualifying2:
+		return 2
+	case Messages.Qualifying3:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// advancing is how many drivers go through to the next part of qualifying, zero when nobody is knocked out. Ten
+// drivers always reach Q3 and half of the rest of the field is knocked out in Q1.
+func advancing(part int, fieldSize int) int {
+	switch part {
+	case 1:
+		return 10 + (fieldSize-10)/2
+	case 2:
+		return 10
+	default:
+		return 0
+	}
+}
+
+// trackQualifyingPart records the longest clock seen in each part of qualifying which is used to tell standard and
+// sprint qualifying apart. The event has the time remaining when the clock was last started so it is the length of
+// the part even when joining part way through.
+func (s *sessionBase) trackQualifyingPart(event Messages.Event) {
+	part := qualifyingPart(event.Type)
+	if part > 0 && event.RemainingTime > s.longestPart[part-1] {
+		s.longestPart[part-1] = event.RemainingTime
+	}
+}
+
+// qualifyingFormat is sprint qualifying if the clock started at the length of a sprint qualifying part. It is
+// unknown until the clock has been started.
+func (s *sessionBase) qualifyingFormat() qualifyingFormat {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	for part, longest := range s.longestPart {
+		switch {
+		case longest > sprintLengths[part]+partLengthTolerance:
+			return standardFormat
+		case longest >= sprintLengths[part]-partLengthTolerance:
+			return sprintFormat
+		}
+	}
+	return unknownFormat
+}
+
+// eventName is the name of the current part of the session, SQ1, SQ2 and SQ3 for sprint qualifying
+func (s *sessionBase) eventName() string {
+	if part := qualifyingPart(s.event.Type); part > 0 && s.qualifyingFormat() == sprintFormat {
+		return fmt.Sprintf("SQ%d", part)
+	}
+	return s.event.Type.String()
+}
+
+// advancing is how many drivers go through from the current part of qualifying, zero outside Q1 and Q2
+func (s *sessionBase) advancing() int {
+	s.dataLock.Lock()
+	fieldSize := len(s.data)
+	s.dataLock.Unlock()
+
+	s.eventLock.Lock()
+	part := qualifyingPart(s.event.Type)
+	s.eventLock.Unlock()
+
+	return advancing(part, fieldSize)
+}
+
+// sectorComplete is true when every segment of the sector has been driven on the current lap
+func sectorComplete(segments []Messages.SegmentType) bool {
+	for _, segment := range segments {
+		if segment == Messages.None {
+			return false
+		}
+	}
+	return len(segments) > 0
+}
+
+// projectedLap is the lap time for a driver on a flying lap using the sectors they have completed and their best
+// times for the rest, less the track evolution. False if they aren't part way through a lap.
+func (s *sessionBase) projectedLap(driver Messages.Timing, evolution time.Duration) (time.Duration, bool) {
+	if driver.Location != Messages.OnTrack || driver.KnockedOutOfQualifying {
+		return 0, false
+	}
+
+	bests := bestsFromLaps(s.history.Laps(driver.Number))
+	personalBests := []time.Duration{bests.sector1, bests.sector2, bests.sector3}
+	sectors := []time.Duration{driver.Sector1, driver.Sector2, driver.Sector3}
+
+	completed := 0
+	var projected time.Duration
+	for x, segments := range s.sectorSegments() {
+		if sectorComplete(driver.Segment[segments[0]:segments[1]]) && sectors[x] > 0 {
+			completed++
+			projected += sectors[x]
+			continue
+		}
+
+		if personalBests[x] == 0 {
+			return 0, false
+		}
+		projected += personalBests[x] - evolution/3
+	}
+
+	// A lap with every sector complete has finished and is in their fastest lap if it was quicker
+	return projected, completed > 0 && completed < len(sectors)
+}
+
+// cutoffPrediction is the slowest time that goes through from the current part of qualifying now and once the
+// drivers on a flying lap have finished
+type cutoffPrediction struct {
+	current   time.Duration
+	predicted time.Duration
+	// Projected lap times for the drivers on a flying lap
+	projections map[int]time.Duration
+}
+
+// cutoff returns the predicted cutoff time, or the current one if there aren't enough times to predict it
+func (c cutoffPrediction) cutoff() time.Duration {
+	if c.predicted > 0 {
+		return c.predicted
+	}
+	return c.current
+}
+
+// predictCutoff works out the cutoff now and once the drivers on a flying lap finish, which is only possible in the
+// parts of qualifying where drivers are knocked out
+func (s *sessionBase) predictCutoff() cutoffPrediction {
+	prediction := cutoffPrediction{projections: make(map[int]time.Duration)}
+
+	advancing := s.advancing()
+	if advancing == 0 {
+		return prediction
+	}
+
+	s.eventLock.Lock()
+	now := s.eventTime
+	s.eventLock.Unlock()
+	evolution, _ := s.history.Improvement(now.Add(-trackEvolutionWindow))
+
+	var current []time.Duration
+	var predicted []time.Duration
+	for _, driver := range s.sortedDrivers() {
+		if driver.KnockedOutOfQualifying {
+			continue
+		}
+
+		best := driver.FastestLap
+		if best > 0 {
+			current = append(current, best)
+		}
+
+		if projected, onFlyingLap := s.projectedLap(driver, evolution); onFlyingLap {
+			prediction.projections[driver.Number] = projected
+			if best == 0 || projected < best {
+				best = projected
+			}
+		}
+
+		if best > 0 {
+			predicted = append(predicted, best)
+		}
+	}
+
+	sort.Slice(current, func(i, j int) bool { return current[i] < current[j] })
+	sort.Slice(predicted, func(i, j int) bool { return predicted[i] < predicted[j] })
+	if len(current) >= advancing {
+		prediction.current = current[advancing-1]
+	}
+	if len(predicted) >= advancing {
+		prediction.predicted = predicted[advancing-1]
+	}
+	return prediction
+}
+
+// cutoffCell is how much the driver needs to improve by to get through, or their margin if they are through. The
+// arrow marks drivers on a flying lap who are on course to get through.
+func cutoffCell(driver Messages.Timing, prediction cutoffPrediction) cell {
+	cutoff := prediction.cutoff()
+	if cutoff == 0 || driver.KnockedOutOfQualifying {
+		return nil
+	}
+
+	var result cell
+	if driver.FastestLap == 0 {
+		result = text("No Time", "#FF0000")
+	} else {
+		delta := driver.FastestLap - cutoff
+		result = text(fmtDegradation(delta), deltaColor(delta))
+	}
+
+	projected, onFlyingLap := prediction.projections[driver.Number]
+	if onFlyingLap && (driver.FastestLap == 0 || driver.FastestLap > cutoff) && projected <= cutoff {
+		result = append(result, span{text: " ▲", color: "#00FF00"})
+	}
+	return result
+}
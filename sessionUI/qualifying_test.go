@@ -0,0 +1,54 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+	"time"
+)
+
+func TestQualifyingCutoff(t *testing.T) {
+	data := qualifyingScript()
+
+	// A sprint qualifying clock with the driver in twelfth part way through a lap quick enough to get through
+	event := testEvent(Messages.Qualifying2, Messages.Started)
+	event.RemainingTime = 10 * time.Minute
+	flying := testDriver(12, 32, "DLX", "#FFFFFF")
+	flying.Sector1 = 29900 * time.Millisecond
+	for x := 3; x < 10; x++ {
+		flying.Segment[x] = Messages.None
+	}
+	data.Add(event, flying)
+
+	session := NewPracticeQualifyingUI(&recordedHTML{}, NewSettings())
+	playSession(t, session, data)
+	checkGolden(t, "qualifying_cutoff", session.View())
+
+	if session.qualifyingFormat() != sprintFormat {
+		t.Error("expected sprint qualifying")
+	}
+	if prediction := session.predictCutoff(); prediction.current != 90600*time.Millisecond ||
+		prediction.predicted != 90540*time.Millisecond || prediction.projections[32] != 90500*time.Millisecond {
+		t.Errorf("unexpected prediction: %+v", prediction)
+	}
+
+	for part, expected := range map[int]int{1: 16, 2: 10, 3: 0} {
+		if actual := advancing(part, 22); actual != expected {
+			t.Errorf("expected %d drivers to go through part %d, got %d", expected, part, actual)
+		}
+	}
+}
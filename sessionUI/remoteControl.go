@@ -0,0 +1,106 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"github.com/gorilla/mux"
+	"net/http"
+	"time"
+)
+
+type control int
+
+const (
+	pauseControl control = iota
+	skipFiveSecondsControl
+	skipMinuteControl
+	skipLapControl
+	sessionStartControl
+	muteControl
+	gapControl
+	speedUpControl
+	slowDownControl
+)
+
+// Names used for the controls in the web server URLs
+var controlNames = map[string]control{
+	"pause":         pauseControl,
+	"skip-5s":       skipFiveSecondsControl,
+	"skip-1m":       skipMinuteControl,
+	"skip-lap":      skipLapControl,
+	"session-start": sessionStartControl,
+	"mute":          muteControl,
+	"gap":           gapControl,
+	"speed-up":      speedUpControl,
+	"slow-down":     slowDownControl,
+}
+
+// control performs a playback action. It is used for both keyboard shortcuts and the web server remote control.
+func (s *sessionBase) control(action control) {
+	switch action {
+	case pauseControl:
+		s.togglePause()
+
+	case skipFiveSecondsControl:
+		s.f.IncrementTime(time.Second * 5)
+
+	case skipMinuteControl:
+		s.f.IncrementTime(time.Minute * 1)
+
+	case skipLapControl:
+		s.f.IncrementLap()
+
+	case sessionStartControl:
+		s.f.SkipToSessionStart()
+
+	case muteControl:
+		s.isMuted.Store(!s.isMuted.Load())
+
+	case gapControl:
+		s.gapToInfront.Store(!s.gapToInfront.Load())
+
+	case speedUpControl:
+		s.changeSpeed(true)
+
+	case slowDownControl:
+		s.changeSpeed(false)
+
+	default:
+		panic("Unhandled control")
+	}
+}
+
+// controlHandler runs the control named in the URL. Authentication is handled by the web server before the
+// request gets here.
+func (s *sessionBase) controlHandler(w http.ResponseWriter, r *http.Request) {
+	action, exists := controlNames[mux.Vars(r)["action"]]
+	if !exists {
+		http.Error(w, "Unknown control", http.StatusNotFound)
+		return
+	}
+
+	// Stop the session being left or restarted while the control uses it
+	s.sourceLock.RLock()
+	defer s.sourceLock.RUnlock()
+
+	if s.f == nil {
+		http.Error(w, "No active session", http.StatusNotFound)
+		return
+	}
+
+	s.control(action)
+	w.WriteHeader(http.StatusNoContent)
+}
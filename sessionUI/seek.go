@@ -0,0 +1,255 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"errors"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// seekTarget is a lap or a time of day at the circuit to move a replay to
+type seekTarget struct {
+	lap  int
+	time time.Time
+}
+
+// parseSeekTarget reads a lap number (optionally starting with L or lap) or a track time of HH:MM or HH:MM:SS on
+// the same day as now in the circuit's timezone
+func parseSeekTarget(value string, now time.Time, timezone *time.Location) (seekTarget, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+
+	if strings.Contains(value, ":") {
+		var clock time.Time
+		var err error
+		if strings.Count(value, ":") == 1 {
+			clock, err = time.Parse("15:04", value)
+		} else {
+			clock, err = time.Parse("15:04:05", value)
+		}
+		if err != nil {
+			return seekTarget{}, fmt.Errorf("invalid track time \"%s\"", value)
+		}
+
+		day := now.In(timezone)
+		return seekTarget{time: time.Date(day.Year(), day.Month(), day.Day(), clock.Hour(), clock.Minute(),
+			clock.Second(), 0, timezone)}, nil
+	}
+
+	lap, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(value, "lap"), "l")))
+	if err != nil || lap < 1 {
+		return seekTarget{}, fmt.Errorf("invalid lap \"%s\"", value)
+	}
+	return seekTarget{lap: lap}, nil
+}
+
+// SetReplaySource gives the session a way to open the replay again from the start so it can seek backwards
+func (s *sessionBase) SetReplaySource(open func() f1gopherlib.F1GopherLib) {
+	s.reopen = open
+}
+
+// submitSeek moves the replay to the lap or time typed into the seek prompt
+func (s *sessionBase) submitSeek(value string) error {
+	s.eventLock.Lock()
+	now := s.eventTime
+	s.eventLock.Unlock()
+
+	target, err := parseSeekTarget(value, now, s.f.CircuitTimezone())
+	if err != nil {
+		return err
+	}
+	return s.seek(target)
+}
+
+// Longest jump made at once when seeking. The replay drops timing data that doesn't fit in its buffers, which hold
+// about five minutes, so longer jumps are made in steps.
+const seekStep = time.Minute
+
+// The replay sends the data up to the session time every third playback tick
+const replayDataCycle = 3 * playbackTick
+
+// How often a seek checks whether the replay has caught up
+const seekPoll = 100 * time.Millisecond
+
+// seek moves the replay to a lap or time. Moving forward skips ahead in the current replay. Moving back opens the
+// replay again from the start and skips ahead from there so everything worked out from the timing is rebuilt.
+// Only races and sprints can skip laps.
+func (s *sessionBase) seek(target seekTarget) error {
+	if s.playbackSpeed() == MaxSpeed {
+		return errors.New("replays at max speed can't seek")
+	}
+	if target.lap > 0 && !s.isRace() {
+		return errors.New("only races and sprints can jump to a lap")
+	}
+	if s.seeking.Load() {
+		return errors.New("still moving to the last jump")
+	}
+
+	s.eventLock.Lock()
+	currentLap := s.event.CurrentLap
+	totalLaps := s.event.TotalLaps
+	now := s.eventTime
+	start := s.replayStart
+	s.eventLock.Unlock()
+
+	if totalLaps > 0 && target.lap > totalLaps {
+		return fmt.Errorf("the race is only %d laps", totalLaps)
+	}
+
+	backwards := (target.lap > 0 && target.lap <= currentLap) || (!target.time.IsZero() && target.time.Before(now))
+	if backwards {
+		if s.reopen == nil || start.IsZero() {
+			return errors.New("this session can't go backwards")
+		}
+		if !target.time.IsZero() && target.time.Before(start) {
+			return errors.New("the replay doesn't go back that far")
+		}
+
+		// The new replay starts from the beginning again
+		s.restart()
+	}
+
+	// The replay only moves while playing so a paused replay plays until the jump has finished
+	s.playbackLock.Lock()
+	if s.paused {
+		s.switchPause()
+		s.pauseAfterSeek = true
+	}
+	s.seeking.Store(true)
+	s.playbackLock.Unlock()
+
+	s.wg.Add(1)
+	go s.stepTo(target, s.quit)
+	return nil
+}
+
+// stepTo skips the replay forward a lap or seekStep at a time. Each step waits for the data it sent to be handled
+// before the next one so none of it is dropped and everything worked out from the timing is built up as it would be
+// when watching.
+func (s *sessionBase) stepTo(target seekTarget, quit chan struct{}) {
+	defer s.wg.Done()
+	defer s.finishSeek(quit)
+
+	// A replay opened again hasn't sent the session time yet
+	if !target.time.IsZero() && !s.waitForReplay(quit, func() bool { return !s.eventTime.IsZero() }) {
+		return
+	}
+
+	for {
+		s.eventLock.Lock()
+		lap := s.event.CurrentLap
+		now := s.eventTime
+		s.eventLock.Unlock()
+
+		var stepEnd time.Time
+		if target.lap > 0 {
+			if lap >= target.lap {
+				return
+			}
+
+			// The replay moves to the time of the event that starts the next lap
+			s.f.IncrementLap()
+			var event Messages.Event
+			if !s.waitForReplay(quit, func() bool {
+				event = s.event
+				return event.CurrentLap > lap
+			}) {
+				return
+			}
+			stepEnd = event.Timestamp
+		} else {
+			if !now.Before(target.time) {
+				return
+			}
+
+			step := min(seekStep, target.time.Sub(now))
+			s.f.IncrementTime(step)
+			stepEnd = now.Add(step)
+		}
+
+		// The timing up to the end of the step has been sent once the session time is a data cycle past it
+		if !s.waitForReplay(quit, func() bool {
+			return !s.eventTime.Before(stepEnd.Add(replayDataCycle)) && len(s.f.Timing()) == 0
+		}) {
+			return
+		}
+	}
+}
+
+// finishSeek pauses the replay again if it was paused when the jump started or during it
+func (s *sessionBase) finishSeek(quit chan struct{}) {
+	s.playbackLock.Lock()
+	defer s.playbackLock.Unlock()
+
+	s.seeking.Store(false)
+	if !s.pauseAfterSeek {
+		return
+	}
+	s.pauseAfterSeek = false
+
+	select {
+	case <-quit:
+		// The session has been left
+	default:
+		s.switchPause()
+	}
+}
+
+// waitForReplay polls until the condition, which is checked with eventLock held, is true. False if the session is
+// left first.
+func (s *sessionBase) waitForReplay(quit chan struct{}, condition func() bool) bool {
+	for {
+		s.eventLock.Lock()
+		done := condition()
+		s.eventLock.Unlock()
+		if done {
+			return true
+		}
+
+		select {
+		case <-quit:
+			return false
+		case <-time.After(seekPoll):
+		}
+	}
+}
+
+// restart leaves the session and enters it again with a new replay from the start, keeping the playback speed and
+// whether it is paused
+func (s *sessionBase) restart() {
+	speed := s.playbackSpeed()
+	s.playbackLock.Lock()
+	paused := s.paused
+	s.playbackLock.Unlock()
+
+	old := s.f
+	s.Leave()
+	// Closing waits for the old replay to stop which can take a while so don't hold up the display
+	go old.Close()
+
+	s.Enter(s.reopen(), s.ui, false)
+
+	s.playbackLock.Lock()
+	s.speed = speed
+	s.playbackLock.Unlock()
+	if paused {
+		s.togglePause()
+	}
+}
@@ -0,0 +1,182 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSeek(t *testing.T) {
+	for value, expected := range map[string]seekTarget{
+		"23":       {lap: 23},
+		"L5":       {lap: 5},
+		"lap 7":    {lap: 7},
+		"14:35":    {time: sessionStart.Add(35 * time.Minute)},
+		"14:35:30": {time: sessionStart.Add(35*time.Minute + 30*time.Second)},
+	} {
+		if target, err := parseSeekTarget(value, sessionStart, time.UTC); err != nil || target.lap != expected.lap || !target.time.Equal(expected.time) {
+			t.Errorf("expected %+v for %s, got %+v %v", expected, value, target, err)
+		}
+	}
+	for _, value := range []string{"", "0", "lap", "25:00", "14:3x"} {
+		if _, err := parseSeekTarget(value, sessionStart, time.UTC); err == nil {
+			t.Errorf("expected an error for \"%s\"", value)
+		}
+	}
+
+	data := raceScript()
+	var reopened *fakeSession.Session
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	session.SetReplaySource(func() f1gopherlib.F1GopherLib {
+		// The start of the race with the race control messages sent before the session was left
+		reopened = fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+		reopened.Add(
+			Messages.EventTime{Timestamp: sessionStart, Remaining: 2 * time.Hour},
+			Messages.RaceControlMessage{Timestamp: sessionStart, Msg: "GREEN LIGHT - PIT EXIT OPEN", Flag: Messages.GreenFlag},
+			Messages.RaceControlMessage{Timestamp: sessionStart.Add(5 * time.Minute), Msg: "YELLOW IN TRACK SECTOR 6", Flag: Messages.YellowFlag})
+		return reopened
+	})
+	playSession(t, session, data)
+
+	seek := func(value string) {
+		session.Update(keyMsg("j"))
+		for _, r := range value {
+			session.Update(keyMsg(string(r)))
+		}
+		session.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	}
+
+	// Forwards skips ahead in the current replay a lap or a minute at a time, the session is on lap 12 at 14:20
+	seek("15")
+	followSeek(t, session, data, 0, 0)
+	if data.LapIncrements() != 3 || session.event.CurrentLap != 15 {
+		t.Errorf("expected three lap increments, got %d", data.LapIncrements())
+	}
+
+	seek("14:30")
+	followSeek(t, session, data, 3, 0)
+	increments := data.TimeIncrements()
+	for _, increment := range increments {
+		if increment > seekStep {
+			t.Errorf("expected at most %s at a time, got %s", seekStep, increment)
+		}
+	}
+	session.eventLock.Lock()
+	now := session.eventTime
+	session.eventLock.Unlock()
+	if len(increments) != 10 || increments[0] != seekStep || now.Before(sessionStart.Add(30*time.Minute)) {
+		t.Errorf("unexpected time increments: %v", increments)
+	}
+
+	// Another jump has to wait for the last one to finish
+	seek("14:40")
+	seek("14:45")
+	if session.prompt != seekPrompt || !strings.Contains(session.View(), "still moving to the last jump") {
+		t.Error("expected the seek prompt to show an error")
+	}
+	session.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Pausing during a jump keeps the replay playing until the jump has finished
+	session.control(pauseControl)
+	if data.IsPaused() {
+		t.Error("expected the replay to keep playing during the jump")
+	}
+	followSeek(t, session, data, 3, len(increments))
+	if !data.IsPaused() {
+		t.Error("expected the replay to be paused after the jump")
+	}
+
+	// Before the replay started can't be reached
+	seek("14:10")
+	if session.prompt != seekPrompt || !strings.Contains(session.View(), "the replay doesn't go back that far") {
+		t.Error("expected the seek prompt to show an error")
+	}
+	session.Update(tea.KeyMsg{Type: tea.KeyEsc})
+
+	// Backwards starts the replay again and skips ahead from the start, playing it for the jump when it is paused
+	data.Add(Messages.RaceControlMessage{Timestamp: sessionStart.Add(19 * time.Minute), Msg: "CAR 4 (NOR) STOPPED"}).Play()
+	seek("L5")
+	if reopened == nil || session.f != reopened {
+		t.Fatal("expected the replay to be opened again")
+	}
+	if session.prompt != noPrompt || len(session.rcMessages) != 0 || len(session.data) != 0 || len(session.radio) != 0 {
+		t.Error("expected the state from the old replay to be cleared")
+	}
+	followSeek(t, session, reopened, 0, 0)
+	if reopened.LapIncrements() != 5 || !reopened.IsPaused() {
+		t.Errorf("expected five lap increments and still paused, got %d", reopened.LapIncrements())
+	}
+
+	session.rcMessagesLock.Lock()
+	messages := len(session.rcMessages)
+	session.rcMessagesLock.Unlock()
+	if messages != 2 {
+		t.Errorf("expected the race control messages to be rebuilt, got %d", messages)
+	}
+}
+
+func TestSeekPractice(t *testing.T) {
+	session := NewPracticeQualifyingUI(&recordedHTML{}, NewSettings())
+	playSession(t, session, qualifyingScript())
+
+	// Only races and sprints go lap by lap
+	if err := session.seek(seekTarget{lap: 5}); err == nil {
+		t.Error("expected an error jumping to a lap")
+	}
+	if err := session.seek(seekTarget{time: sessionStart.Add(10 * time.Minute)}); err != nil {
+		t.Error(err)
+	}
+}
+
+// followSeek plays the replay forward as a seek steps through it and returns once the seek has finished. The laps and
+// time increments are how many the replay had been sent before the seek.
+func followSeek(t *testing.T, session *raceUI, data *fakeSession.Session, laps int, increments int) {
+	t.Helper()
+
+	data.Play()
+	deadline := time.Now().Add(5 * time.Second)
+	for session.seeking.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("the seek didn't finish")
+		}
+
+		session.eventLock.Lock()
+		lap := session.event.CurrentLap
+		now := session.eventTime
+		session.eventLock.Unlock()
+
+		// Each step is answered with the data up to a data cycle after it
+		if data.LapIncrements() > laps {
+			laps++
+			event := testEvent(Messages.Race, Messages.Started)
+			event.CurrentLap = lap + 1
+			event.Timestamp = now
+			data.Add(event, Messages.EventTime{Timestamp: now.Add(replayDataCycle), Remaining: time.Hour}).Play()
+		} else if timeIncrements := data.TimeIncrements(); len(timeIncrements) > increments {
+			data.Add(Messages.EventTime{Timestamp: now.Add(timeIncrements[increments] + replayDataCycle), Remaining: time.Hour}).Play()
+			increments++
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -17,13 +17,13 @@ package sessionUI
 
 import (
 	"bytes"
+	"f1gopher/f1gopher-cmdline/history"
 	"f1gopher/f1gopher-cmdline/ui"
 	"fmt"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/f1gopher/f1gopherlib"
 	"github.com/f1gopher/f1gopherlib/Messages"
-	"github.com/gorilla/mux"
 	"github.com/hajimehoshi/go-mp3"
 	"github.com/hajimehoshi/oto/v2"
 	"net/http"
@@ -46,10 +46,15 @@ type sessionBase struct {
 	tick          func() (updateContent bool)
 	err           error
 	ui            ui.Page
+	page          ui.Page
 	currentWidth  int
 	currentHeight int
 
-	f        f1gopherlib.F1GopherLib
+	f f1gopherlib.F1GopherLib
+	// Held while the data source is swapped so the web server can use it from its own goroutines. The UI
+	// goroutine is the only one that swaps it so doesn't need the lock to read it.
+	sourceLock sync.RWMutex
+
 	data     map[int]Messages.Timing
 	dataLock sync.Mutex
 
@@ -66,13 +71,15 @@ type sessionBase struct {
 	weather     Messages.Weather
 	weatherLock sync.Mutex
 
+	// Guarded by eventLock
 	eventTime     time.Time
 	remainingTime time.Duration
-	isMuted       bool
-	gapToInfront  bool
+	isMuted       atomic.Bool
+	gapToInfront  atomic.Bool
 
 	wg   sync.WaitGroup
 	exit atomic.Bool
+	quit chan struct{}
 
 	fastestSector1        time.Duration
 	fastestSector2        time.Duration
@@ -80,35 +87,136 @@ type sessionBase struct {
 	theoreticalFastestLap time.Duration
 	previousSessionActive Messages.SessionState
 	fastestSpeedTrap      int
+	// Number of the driver who set each of the fastest sectors
+	fastestSectorDrivers [3]int
+	driverBests          map[int]*driverBest
+	fastestLock          sync.Mutex
 
 	driverGapTrend map[int]driverTrend
 	driverGapLock  sync.Mutex
 
-	servers          []string
-	html             string
+	history   *history.History
+	positions *history.Positions
+
+	web              WebPublisher
+	webHandler       http.Handler
 	liveDelay        time.Duration
 	liveStartTime    time.Time
 	liveDelayExpired bool
 
-	renderDataForScreen func(segmentCount int, remaining string, v []Messages.Timing) (table string, separator string)
-	renderDataForHtml   func(segmentCount int, remaining string, v []Messages.Timing) (table string, separator string)
+	layouts   *ColumnLayouts
+	picker    *columnPicker
+	pitLosses *PitLaneLosses
+	standings *Standings
+
+	// Index of the highlighted row in the timing tower, -1 when no row is selected
+	cursor         int
+	selectedDriver int
+	compareDrivers []int
+	pageScroll     int
+
+	// Drivers plotted on the gap chart, the driver the gaps are to (0 for the leader) and the highlighted driver
+	chartDrivers   map[int]bool
+	chartReference int
+	chartCursor    int
+
+	battleSettings BattleSettings
+	// Gap for each battle at the last timing update and the alerts for battles that have got close
+	battleGaps   map[int]time.Duration
+	battleAlerts []battleAlert
+	battleLock   sync.Mutex
+
+	// Longest clock seen in each part of qualifying, used to spot sprint qualifying
+	longestPart [3]time.Duration
+
+	// Show the projected finishing order instead of the current order
+	projecting bool
+
+	// Worked out for the whole field once per render and shared by every row of the timing tower
+	towerValues towerValues
+
+	isLive bool
+	// Replay speed chosen at launch and the current speed. Paused is set when the user pauses and halted while a
+	// replay is stopped for half speed playback. A replay keeps playing during a jump and is paused once it finishes
+	// if pauseAfterSeek is set.
+	launchSpeed    PlaybackSpeed
+	speed          PlaybackSpeed
+	paused         bool
+	halted         bool
+	pauseAfterSeek bool
+	playbackLock   sync.Mutex
+
+	// Opens the replay again from the start and the first session time it sent, used to seek backwards. Seeking is
+	// set while the replay is being moved to the place jumped to.
+	reopen      func() f1gopherlib.F1GopherLib
+	replayStart time.Time
+	seeking     atomic.Bool
+	// Prompt open in the status line, the text typed into it and why the last attempt failed
+	prompt      promptKind
+	promptText  string
+	promptError string
+
+	// Bookmarks saved for the session, those made from race control messages so far (guarded by rcMessagesLock)
+	// and the moment to bookmark while the note is typed
+	bookmarks       *Bookmarks
+	autoBookmarks   []Bookmark
+	pendingBookmark Bookmark
+	bookmarkCursor  int
+	bookmarkError   string
+
+	titleForScreen func(remaining string) string
+	titleForHtml   func(remaining string) string
+	rowBackground  func(index int, driver Messages.Timing) string
 }
 
 func (s *sessionBase) Enter(data f1gopherlib.F1GopherLib, ui ui.Page, isLive bool) {
 	s.exit.Store(false)
+	s.sourceLock.Lock()
 	s.f = data
+	s.sourceLock.Unlock()
 	s.ui = ui
+	s.page = ui
+	s.cursor = -1
+	s.compareDrivers = nil
+	s.chartDrivers = nil
+	s.chartReference = 0
+	s.chartCursor = 0
+	s.longestPart = [3]time.Duration{}
+	s.projecting = false
+	s.isLive = isLive
+	s.speed = NormalSpeed
+	if !isLive {
+		s.speed = s.launchSpeed
+	}
+	s.paused = false
+	s.halted = false
+	s.pauseAfterSeek = false
+	s.replayStart = time.Time{}
+	s.prompt = noPrompt
+	s.battleGaps = nil
+	s.battleAlerts = nil
 	s.fastestSector1 = 0
 	s.fastestSector2 = 0
 	s.fastestSector3 = 0
 	s.theoreticalFastestLap = 0
+	s.fastestSpeedTrap = 0
+	s.fastestSectorDrivers = [3]int{}
+	s.driverBests = make(map[int]*driverBest)
 	s.previousSessionActive = Messages.Inactive
 	s.driverGapTrend = make(map[int]driverTrend, 0)
 	s.liveDelayExpired = false
+	s.quit = make(chan struct{})
 
+	s.wg.Add(2)
 	go s.listen()
 	go s.playTeamRadio()
 
+	// Max speed replays aren't paced so there is nothing to adjust
+	if !isLive && s.speed != MaxSpeed {
+		s.wg.Add(1)
+		go s.playAtSpeed()
+	}
+
 	if isLive {
 		s.liveStartTime = time.Now().Add(s.liveDelay)
 
@@ -122,27 +230,37 @@ func (s *sessionBase) Enter(data f1gopherlib.F1GopherLib, ui ui.Page, isLive boo
 		s.liveDelayExpired = true
 	}
 
-	s.gapToInfront = data.Session() == Messages.RaceSession || data.Session() == Messages.SprintSession
+	s.gapToInfront.Store(data.Session() == Messages.RaceSession || data.Session() == Messages.SprintSession)
 }
 
 func (s *sessionBase) Leave() {
 
 	// TODO - tell f1data to quit
 	s.exit.Store(true)
+	close(s.quit)
 	s.wg.Wait()
 
+	s.sourceLock.Lock()
 	s.f = nil
+	s.sourceLock.Unlock()
 	s.data = make(map[int]Messages.Timing)
 	s.event = Messages.Event{}
 	s.rcMessages = make([]Messages.RaceControlMessage, 0)
+	s.autoBookmarks = nil
 	s.radio = make([]Messages.Radio, 0)
 	s.radioName = ""
 	s.weather = Messages.Weather{}
+	s.eventLock.Lock()
 	s.eventTime = time.Time{}
 	s.remainingTime = 0
+	s.eventLock.Unlock()
 	s.driverGapTrend = make(map[int]driverTrend, 0)
+	s.history.Clear()
+	s.positions.Clear()
+}
 
-	s.html = ""
+func (s *sessionBase) WebHandler() http.Handler {
+	return s.webHandler
 }
 
 func (s *sessionBase) Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd) {
@@ -150,39 +268,162 @@ func (s *sessionBase) Update(msg tea.Msg) (newUI ui.Page, cmds []tea.Cmd) {
 
 	switch msgType := msg.(type) {
 	case tea.KeyMsg:
+		// While the column picker is open it gets all the key presses
+		if s.picker != nil {
+			if !s.picker.update(msgType) {
+				s.picker = nil
+			}
+			return s.page, nil
+		}
+
+		// As does a prompt
+		if s.prompt != noPrompt {
+			s.updatePrompt(msgType)
+			return s.page, nil
+		}
+
+		if s.page == ui.GapChart && s.updateGapChart(msgType) {
+			return s.page, nil
+		}
+
+		if s.page == ui.Bookmarks && s.updateBookmarks(msgType) {
+			return s.page, nil
+		}
+
+		// Pages that scroll
+		switch s.page {
+		case ui.DriverDetail, ui.Comparison, ui.StintTimeline, ui.PaceAnalysis, ui.Standings:
+			switch msgType.Type {
+			case tea.KeyEsc:
+				s.page = s.ui
+				s.compareDrivers = nil
+				return s.page, nil
+
+			case tea.KeyUp:
+				s.pageScroll = max(0, s.pageScroll-1)
+				return s.page, nil
+
+			case tea.KeyDown:
+				s.pageScroll++
+				return s.page, nil
+			}
+		}
+
 		switch msgType.Type {
 		case tea.KeyEsc:
+			// Clear the selected row and drivers to compare before leaving the session
+			if s.cursor >= 0 || len(s.compareDrivers) > 0 {
+				s.cursor = -1
+				s.compareDrivers = nil
+				return s.page, nil
+			}
 			return ui.MainMenu, nil
 
 		case tea.KeyUp:
-			s.f.IncrementTime(time.Minute * 1)
+			s.control(skipMinuteControl)
+
+		// Shift moves the selected row so it doesn't clash with skipping forward
+		case tea.KeyShiftUp:
+			s.cursor = max(0, s.cursor-1)
+
+		case tea.KeyShiftDown:
+			s.dataLock.Lock()
+			s.cursor = min(s.cursor+1, len(s.data)-1)
+			s.dataLock.Unlock()
+
+		case tea.KeyEnter:
+			drivers := s.tower(s.sortedDrivers())
+			if s.page == s.ui && s.cursor >= 0 && s.cursor < len(drivers) {
+				s.selectedDriver = drivers[s.cursor].Number
+				s.pageScroll = 0
+				s.page = ui.DriverDetail
+			}
 
 		case tea.KeyCtrlCloseBracket:
-			s.f.IncrementTime(time.Second * 5)
+			s.control(skipFiveSecondsControl)
 
 		case tea.KeyRight:
-			s.f.IncrementLap()
+			s.control(skipLapControl)
 
 		default:
 			switch msgType.String() {
 			case "r":
-				s.isMuted = !s.isMuted
+				s.control(muteControl)
 
 			case "t":
-				s.gapToInfront = !s.gapToInfront
+				s.control(gapControl)
 
 			case "p":
-				s.f.TogglePause()
+				s.control(pauseControl)
 
 			case "s":
-				s.f.SkipToSessionStart()
+				s.control(sessionStartControl)
+
+			case "c":
+				s.picker = newColumnPicker(s.layouts, s.isRace())
+
+			case "v":
+				drivers := s.tower(s.sortedDrivers())
+				if s.page == s.ui && s.cursor >= 0 && s.cursor < len(drivers) && s.toggleCompare(drivers[s.cursor].Number) {
+					s.pageScroll = 0
+					s.page = ui.Comparison
+				}
+
+			case "g":
+				if s.page == s.ui {
+					s.openGapChart()
+				}
+
+			case "l":
+				if s.page == s.ui && s.isRace() {
+					s.pageScroll = 0
+					s.page = ui.StintTimeline
+				}
+
+			case "a":
+				if s.page == s.ui && s.isRace() {
+					s.pageScroll = 0
+					s.page = ui.PaceAnalysis
+				}
+
+			case "f":
+				if s.page == s.ui && s.isRace() {
+					s.projecting = !s.projecting
+				}
+
+			case "+", "=":
+				s.control(speedUpControl)
+
+			case "-":
+				s.control(slowDownControl)
+
+			case "w":
+				if s.page == s.ui && s.isRace() {
+					s.pageScroll = 0
+					s.page = ui.Standings
+				}
+
+			case "j":
+				if s.page == s.ui && !s.isLive {
+					s.openPrompt(seekPrompt)
+				}
+
+			case "b":
+				if s.page == s.ui && !s.isLive && s.bookmarks != nil {
+					s.startBookmark()
+				}
+
+			case "B":
+				if s.page == s.ui && !s.isLive {
+					s.openBookmarks()
+				}
 			}
 		}
 
 	}
 
 	cmds = append(cmds, cmd)
-	return s.ui, cmds
+	return s.page, cmds
 }
 
 func (s *sessionBase) Resize(msg tea.WindowSizeMsg) {
@@ -190,18 +431,41 @@ func (s *sessionBase) Resize(msg tea.WindowSizeMsg) {
 	s.currentHeight = msg.Height
 }
 
+// processedNotifier is implemented by data sources that need to know when a message has been handled, such as the
+// fake session the tests play messages from
+type processedNotifier interface {
+	Processed()
+}
+
 func (s *sessionBase) listen() {
-	s.wg.Add(1)
+	defer s.wg.Done()
+
+	notifier, _ := s.f.(processedNotifier)
 
 	for !s.exit.Load() {
 		select {
+		case <-s.quit:
+			return
+
 		case msg2 := <-s.f.Timing():
 			s.dataLock.Lock()
 			s.data[msg2.Number] = msg2
 			s.dataLock.Unlock()
 
+			s.eventLock.Lock()
+			event := s.event
+			s.eventLock.Unlock()
+			s.history.Add(msg2, event)
+
+			s.fastestLock.Lock()
+			s.updateDriverBest(msg2)
+			s.fastestLock.Unlock()
+
 			// For races calculate the gap to the car in  front trend
 			if s.f.Session() == Messages.RaceSession || s.f.Session() == Messages.SprintSession {
+				s.positions.Add(msg2)
+				s.checkBattleAlerts()
+
 				s.driverGapLock.Lock()
 				for x := range s.data {
 					gap := s.data[x].TimeDiffToPositionAhead.Milliseconds()
@@ -242,15 +506,28 @@ func (s *sessionBase) listen() {
 		case msg := <-s.f.Event():
 			s.eventLock.Lock()
 			s.event = msg
+			s.trackQualifyingPart(msg)
 			s.eventLock.Unlock()
 
 		case msg3 := <-s.f.Time():
+			s.eventLock.Lock()
 			s.eventTime = msg3.Timestamp
+			if s.replayStart.IsZero() {
+				s.replayStart = msg3.Timestamp
+			}
 			s.remainingTime = msg3.Remaining
+			s.eventLock.Unlock()
 
 		case msg4 := <-s.f.RaceControlMessages():
+			s.eventLock.Lock()
+			lap := s.event.CurrentLap
+			s.eventLock.Unlock()
+
 			s.rcMessagesLock.Lock()
 			s.rcMessages = append(s.rcMessages, msg4)
+			if note, notable := automaticBookmark(msg4); notable {
+				s.autoBookmarks = append(s.autoBookmarks, Bookmark{Time: msg4.Timestamp, Lap: lap, Note: note + ": " + msg4.Msg, automatic: true})
+			}
 			s.rcMessagesLock.Unlock()
 
 		case msg5 := <-s.f.Radio():
@@ -263,13 +540,15 @@ func (s *sessionBase) listen() {
 			s.weather = msg6
 			s.weatherLock.Unlock()
 		}
-	}
 
-	s.wg.Done()
+		if notifier != nil {
+			notifier.Processed()
+		}
+	}
 }
 
 func (s *sessionBase) playTeamRadio() {
-	s.wg.Add(1)
+	defer s.wg.Done()
 
 	c, ready, err := oto.NewContext(48000, 2, 2)
 	if err != nil {
@@ -279,23 +558,25 @@ func (s *sessionBase) playTeamRadio() {
 
 	for !s.exit.Load() {
 
-		if len(s.radio) > 0 {
-			s.radioLock.Lock()
-			currentMsg := s.radio[0]
+		s.radioLock.Lock()
+		pending := len(s.radio) > 0
+		var currentMsg Messages.Radio
+		if pending {
+			currentMsg = s.radio[0]
 			s.radio = s.radio[1:]
-			s.radioLock.Unlock()
+		}
+		s.radioLock.Unlock()
 
-			if !s.isMuted {
-				if s.play(currentMsg, c) {
-					return
-				}
+		if pending {
+			// Messages that fail to decode are dropped so the rest of the queue still plays. Radio is muted above
+			// normal speed because the replay skips ahead of it.
+			if !s.isMuted.Load() && s.playbackSpeed() <= NormalSpeed {
+				s.play(currentMsg, c)
 			}
 		}
 
 		time.Sleep(time.Second * 1)
 	}
-
-	s.wg.Done()
 }
 
 func (s *sessionBase) play(currentMsg Messages.Radio, c *oto.Context) bool {
@@ -328,55 +609,6 @@ func (s *sessionBase) play(currentMsg Messages.Radio, c *oto.Context) bool {
 	return false
 }
 
-func (s *sessionBase) webServer() {
-	router := mux.NewRouter()
-	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		abc := `<html>
-<title>GopherF1</title>
-<head>    
-	<meta charset="utf-8">
-</head>
-<script language="javascript">	
-	async function subscribe() {
-  		let response = await fetch("/data");
-	
-		let message = await response.text();
-		document.getElementById("display").innerHTML = message;
-		
-		await new Promise(resolve => setTimeout(resolve, 1000));
-		await subscribe();
-  	}
-
-	subscribe();
-
-</script>
-<body style="background-color:black; color:white">
-	<div>
-		<pre id="display"></pre>
-	</div>
-</body>
-</html>`
-
-		w.Write([]byte(abc))
-	})
-
-	router.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
-		w.Write([]byte(s.html))
-	})
-
-	for x := range s.servers {
-
-		srv := &http.Server{
-			Handler:      router,
-			Addr:         s.servers[x],
-			WriteTimeout: 15 * time.Second,
-			ReadTimeout:  15 * time.Second,
-		}
-
-		go srv.ListenAndServe()
-	}
-}
-
 func (s *sessionBase) View() string {
 	if s.liveStartTime.After(time.Now()) {
 		return lipgloss.Place(s.currentWidth, s.currentHeight, lipgloss.Center, lipgloss.Center,
@@ -389,39 +621,25 @@ func (s *sessionBase) View() string {
 		}
 	}
 
-	hour := int(s.remainingTime.Seconds() / 3600)
-	minute := int(s.remainingTime.Seconds()/60) % 60
-	second := int(s.remainingTime.Seconds()) % 60
-	remaining := fmt.Sprintf("%d:%02d:%02d", hour, minute, second)
-	v := make([]Messages.Timing, 0)
-
-	s.dataLock.Lock()
-	for _, a := range s.data {
-		v = append(v, a)
-	}
-	s.dataLock.Unlock()
-
-	sort.Slice(v, func(i, j int) bool {
-		return v[i].Position < v[j].Position
-	})
-
-	segmentCount := s.event.TotalSegments
-	if segmentCount == 0 {
-		segmentCount = len("Segment")
-	}
+	remaining := fmtRemaining(s.sessionRemaining())
+	v := s.sortedDrivers()
 
 	// Track the fastest sectors times for the session
+	s.fastestLock.Lock()
 	for _, driver := range v {
-		if (driver.Sector1 > 0 && driver.Sector1 < s.fastestSector1) || s.fastestSector1 == 0 {
+		if driver.Sector1 > 0 && (driver.Sector1 < s.fastestSector1 || s.fastestSector1 == 0) {
 			s.fastestSector1 = driver.Sector1
+			s.fastestSectorDrivers[0] = driver.Number
 		}
 
-		if (driver.Sector2 > 0 && driver.Sector2 < s.fastestSector2) || s.fastestSector2 == 0 {
+		if driver.Sector2 > 0 && (driver.Sector2 < s.fastestSector2 || s.fastestSector2 == 0) {
 			s.fastestSector2 = driver.Sector2
+			s.fastestSectorDrivers[1] = driver.Number
 		}
 
-		if (driver.Sector3 > 0 && driver.Sector3 < s.fastestSector3) || s.fastestSector3 == 0 {
+		if driver.Sector3 > 0 && (driver.Sector3 < s.fastestSector3 || s.fastestSector3 == 0) {
 			s.fastestSector3 = driver.Sector3
+			s.fastestSectorDrivers[2] = driver.Number
 		}
 
 		if driver.SpeedTrap > s.fastestSpeedTrap {
@@ -439,6 +657,7 @@ func (s *sessionBase) View() string {
 			s.fastestSector2 = 0
 			s.fastestSector3 = 0
 			s.theoreticalFastestLap = 0
+			s.fastestSectorDrivers = [3]int{}
 			s.previousSessionActive = s.event.Status
 		}
 	} else if s.event.Status == Messages.Inactive {
@@ -446,52 +665,64 @@ func (s *sessionBase) View() string {
 		s.fastestSector2 = 0
 		s.fastestSector3 = 0
 		s.theoreticalFastestLap = 0
+		s.fastestSectorDrivers = [3]int{}
 		s.previousSessionActive = s.event.Status
 	} else {
 		s.previousSessionActive = s.event.Status
 	}
+	s.fastestLock.Unlock()
+
+	s.updateTowerValues()
+	columns := fitColumns(s.columns(), s.currentWidth)
+	separator := columnSeparator(columns)
+	title := s.projectionLabel(false) + s.titleForScreen(remaining)
+	if s.currentWidth > 0 {
+		title = lipgloss.NewStyle().MaxWidth(s.currentWidth).Render(title)
+	}
+	table := s.renderTable(title, columns, separator, s.tower(v), false)
 
-	table, separator := s.renderDataForScreen(segmentCount, remaining, v)
+	table += separator + "\n"
+	table += s.renderFooter(columns, false) + "\n"
+	owners, hasOwners := s.renderOwners(columns, false)
+	if hasOwners {
+		table += owners + "\n"
+	}
 
 	table += separator + "\n"
-	trackStatus := "Track Status: |"
-	for x := 0; x < segmentCount; x++ {
-		switch s.event.SegmentFlags[x] {
-		case Messages.GreenFlag:
-			trackStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("#00FF00")).Render("■")
-		case Messages.YellowFlag:
-			trackStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFF00")).Render("■")
-		case Messages.DoubleYellowFlag:
-			trackStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("#FBFF00")).Render("■")
-		case Messages.RedFlag:
-			trackStatus += lipgloss.NewStyle().Foreground(lipgloss.Color("#FF0000")).Render("■")
-		}
-		if x == s.event.Sector1Segments-1 || x == s.event.Sector1Segments+s.event.Sector2Segments-1 {
-			trackStatus += "|"
-		}
+	drivers := driversByNumber(v)
+	battleLines := s.battleLines(s.battles(), drivers, false)
+	if len(battleLines) > 0 {
+		table += strings.Join(battleLines, "\n") + "\n" + separator + "\n"
 	}
-	if s.f.Session() == Messages.RaceSession || s.f.Session() == Messages.SprintSession {
-		trackStatus += fmt.Sprintf("|                       |%s|%s|%s|%s|                                    |%s|",
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector1)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector2)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector3)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.theoreticalFastestLap)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(12).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmt.Sprintf("%d", s.fastestSpeedTrap)))
-	} else {
-		trackStatus += fmt.Sprintf("|                       |%s|%s|%s|%s|                |%s|",
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector1)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector2)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.fastestSector3)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmtDuration(s.theoreticalFastestLap)),
-			lipgloss.NewStyle().Align(lipgloss.Center).Width(12).Padding(0, 1, 0, 1).Foreground(lipgloss.Color("#D500D5")).Render(fmt.Sprintf("%d", s.fastestSpeedTrap)))
+
+	// Only show as many race control messages as there is room for, the rest of the display is the title,
+	// header, driver rows, track status, session best drivers, battles, status line and four separators
+	usedLines := len(v) + 8
+	if hasOwners {
+		usedLines++
+	}
+	if len(battleLines) > 0 {
+		usedLines += len(battleLines) + 1
+	}
+	rcCount := 5
+	if s.currentHeight > 0 {
+		rcCount = min(rcCount, max(0, s.currentHeight-usedLines))
 	}
 
-	table += trackStatus + "\n"
+	// The overtake feed goes beside the race control messages with the width split between them
+	var overtakes []string
+	if s.isRace() {
+		overtakes = s.overtakeLines(rcCount, drivers, false)
+	}
+	messageWidth := s.currentWidth
+	if len(overtakes) > 0 && s.currentWidth > 0 {
+		messageWidth = s.currentWidth / 2
+	}
 
-	table += separator + "\n"
+	var rcLines []string
 	s.rcMessagesLock.Lock()
 	if len(s.rcMessages) > 0 {
-		for x := len(s.rcMessages) - 1; x >= 0 && x >= len(s.rcMessages)-5; x-- {
+		for x := len(s.rcMessages) - 1; x >= 0 && x >= len(s.rcMessages)-rcCount; x-- {
 			lastMessage := s.rcMessages[x]
 			prefix := ""
 
@@ -521,12 +752,32 @@ func (s *sessionBase) View() string {
 					lipgloss.NewStyle().Foreground(lipgloss.Color("#FFFFFF")).Render("⚑ ")
 			}
 
-			table += fmt.Sprintf("%s - %s%s\n", lastMessage.Timestamp.In(s.f.CircuitTimezone()).Format("02-01-2006 15:04:05"), prefix, lastMessage.Msg)
+			// Keep each message on one line so it only takes the space allowed for it
+			msg := lastMessage.Msg
+			if messageWidth > 0 {
+				msg = truncate(msg, messageWidth-len("02-01-2006 15:04:05 - ")-lipgloss.Width(prefix))
+			}
+
+			rcLines = append(rcLines, fmt.Sprintf("%s - %s%s", lastMessage.Timestamp.In(s.f.CircuitTimezone()).Format("02-01-2006 15:04:05"), prefix, msg))
 		}
 	}
 	s.rcMessagesLock.Unlock()
 
-	status := separator + "\n"
+	if len(overtakes) > 0 && len(rcLines) == 0 {
+		table += strings.Join(overtakes, "\n") + "\n"
+	} else if len(overtakes) > 0 {
+		messages := lipgloss.NewStyle().Width(messageWidth).Render(strings.Join(rcLines, "\n"))
+		feed := strings.Join(overtakes, "\n")
+		if s.currentWidth > 0 {
+			feed = lipgloss.NewStyle().MaxWidth(s.currentWidth - messageWidth - 3).Render(feed)
+		}
+		divider := strings.TrimSuffix(strings.Repeat(" │ \n", max(len(rcLines), len(overtakes))), "\n")
+		table += lipgloss.JoinHorizontal(lipgloss.Top, messages, divider, feed) + "\n"
+	} else if len(rcLines) > 0 {
+		table += strings.Join(rcLines, "\n") + "\n"
+	}
+
+	status := ""
 
 	s.weatherLock.Lock()
 	status += fmt.Sprintf("Air Temp: %.2f°C, Track Temp: %.2f°C, ", s.weather.AirTemp, s.weather.TrackTemp)
@@ -535,7 +786,9 @@ func (s *sessionBase) View() string {
 	}
 	s.weatherLock.Unlock()
 
-	if !s.isMuted {
+	if !s.isMuted.Load() && s.playbackSpeed() > NormalSpeed {
+		status += fmt.Sprintf("Team Radio: Off above 1x")
+	} else if !s.isMuted.Load() {
 		status += fmt.Sprintf("Team Radio: On")
 	} else {
 		status += fmt.Sprintf("Team Radio: Off")
@@ -552,65 +805,126 @@ func (s *sessionBase) View() string {
 			fmt.Sprintf("Session Starts in: %s", fmtCountdown(s.f.SessionStart().Sub(s.eventTime))))
 	}
 
-	if s.f.IsPaused() {
+	if speed := s.playbackSpeed(); speed != NormalSpeed {
+		status += fmt.Sprintf(", Speed: %s", speed)
+	}
+
+	if s.seeking.Load() {
+		status += ", Jumping..."
+	}
+
+	if s.isPaused() {
 		status += fmt.Sprintf(", ** PAUSED **")
 	}
 
-	table += status
+	if s.prompt != noPrompt {
+		status = s.promptLine()
+	}
+
+	if s.currentWidth > 0 {
+		status = lipgloss.NewStyle().MaxWidth(s.currentWidth).Render(status)
+	}
+
+	table += separator + "\n" + status
 
 	s.updateHTML(v)
 
+	if s.picker != nil {
+		return s.picker.View()
+	}
+
+	switch s.page {
+	case ui.DriverDetail:
+		return s.driverDetailView(false)
+	case ui.Comparison:
+		return s.comparisonView(false)
+	case ui.GapChart:
+		return s.gapChartView(false)
+	case ui.StintTimeline:
+		return s.stintTimelineView(false)
+	case ui.PaceAnalysis:
+		return s.paceAnalysisView(false)
+	case ui.Standings:
+		return s.standingsView(false)
+	case ui.Bookmarks:
+		return s.bookmarksView(false)
+	}
+
 	return table
 }
 
+// sessionRemaining is how long is left on the session clock
+func (s *sessionBase) sessionRemaining() time.Duration {
+	s.eventLock.Lock()
+	defer s.eventLock.Unlock()
+
+	return s.remainingTime
+}
+
+func fmtRemaining(remaining time.Duration) string {
+	hour := int(remaining.Seconds() / 3600)
+	minute := int(remaining.Seconds()/60) % 60
+	second := int(remaining.Seconds()) % 60
+	return fmt.Sprintf("%d:%02d:%02d", hour, minute, second)
+}
+
+// sortedDrivers returns the latest timing for each driver in position order
+func (s *sessionBase) sortedDrivers() []Messages.Timing {
+	v := make([]Messages.Timing, 0)
+
+	s.dataLock.Lock()
+	for _, a := range s.data {
+		v = append(v, a)
+	}
+	s.dataLock.Unlock()
+
+	sort.Slice(v, func(i, j int) bool {
+		return v[i].Position < v[j].Position
+	})
+	return v
+}
+
 func (s *sessionBase) updateHTML(v []Messages.Timing) {
-	hour := int(s.remainingTime.Seconds() / 3600)
-	minute := int(s.remainingTime.Seconds()/60) % 60
-	second := int(s.remainingTime.Seconds()) % 60
-	remaining := fmt.Sprintf("%d:%02d:%02d", hour, minute, second)
-	segmentCount := s.event.TotalSegments
-	if segmentCount == 0 {
-		segmentCount = len("Segment")
+	// The web server mirrors the other pages while they are open
+	switch s.page {
+	case ui.DriverDetail:
+		s.web.Publish(s.driverDetailView(true))
+		return
+	case ui.Comparison:
+		s.web.Publish(s.comparisonView(true))
+		return
+	case ui.GapChart:
+		s.web.Publish(s.gapChartView(true))
+		return
+	case ui.StintTimeline:
+		s.web.Publish(s.stintTimelineView(true))
+		return
+	case ui.PaceAnalysis:
+		s.web.Publish(s.paceAnalysisView(true))
+		return
+	case ui.Standings:
+		s.web.Publish(s.standingsView(true))
+		return
+	case ui.Bookmarks:
+		s.web.Publish(s.bookmarksView(true))
+		return
 	}
 
-	table, separator := s.renderDataForHtml(segmentCount, remaining, v)
+	remaining := fmtRemaining(s.sessionRemaining())
+	columns := s.columns()
+	separator := columnSeparator(columns)
+	table := s.renderTable(s.projectionLabel(true)+s.titleForHtml(remaining), columns, separator, s.tower(v), true)
 
 	table += separator + "\n"
-	trackStatus := "Track Status: |"
-	for x := 0; x < segmentCount; x++ {
-		switch s.event.SegmentFlags[x] {
-		case Messages.GreenFlag:
-			trackStatus += "<font color=\"#00FF00\">&#x25a0;</font>"
-		case Messages.YellowFlag:
-			trackStatus += "<font color=\"#FFFF00\">&#x25a0;</font>"
-		case Messages.DoubleYellowFlag:
-			trackStatus += "<font color=\"#FBFF00\">&#x25a0;</font>"
-		case Messages.RedFlag:
-			trackStatus += "<font color=\"#FF0000\">&#x25a0;</font>"
-		}
-		if x == s.event.Sector1Segments-1 || x == s.event.Sector1Segments+s.event.Sector2Segments-1 {
-			trackStatus += "|"
-		}
+	table += s.renderFooter(columns, true) + "\n"
+	if owners, hasOwners := s.renderOwners(columns, true); hasOwners {
+		table += owners + "\n"
 	}
-	if s.f.Session() == Messages.RaceSession || s.f.Session() == Messages.SprintSession {
-		trackStatus += fmt.Sprintf("|                   |%s|%s|%s|%s|                        |%s|",
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth-2).Render(fmtDuration(s.fastestSector1))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth-2).Render(fmtDuration(s.fastestSector2))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth-2).Render(fmtDuration(s.fastestSector3))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth-2).Render(fmtDuration(s.theoreticalFastestLap))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(5).Render(fmt.Sprintf("%d", s.fastestSpeedTrap))))
-	} else {
-		trackStatus += fmt.Sprintf("|                       |%s|%s|%s|%s|                |%s|",
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Render(fmtDuration(s.fastestSector1))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Render(fmtDuration(s.fastestSector2))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Render(fmtDuration(s.fastestSector3))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(timeWidth).Padding(0, 1, 0, 1).Render(fmtDuration(s.theoreticalFastestLap))),
-			fmt.Sprintf("<font color=\"#D500D5\">%s</font>", lipgloss.NewStyle().Align(lipgloss.Center).Width(5).Render(fmt.Sprintf("%d", s.fastestSpeedTrap))))
-	}
-
-	table += trackStatus + "\n"
 
 	table += separator + "\n"
+	if battleLines := s.battleLines(s.battles(), driversByNumber(v), true); len(battleLines) > 0 {
+		table += strings.Join(battleLines, "\n") + "\n" + separator + "\n"
+	}
 	s.rcMessagesLock.Lock()
 	if len(s.rcMessages) > 0 {
 		for x := len(s.rcMessages) - 1; x >= 0 && x >= len(s.rcMessages)-19; x-- {
@@ -647,6 +961,12 @@ func (s *sessionBase) updateHTML(v []Messages.Timing) {
 	}
 	s.rcMessagesLock.Unlock()
 
+	if s.isRace() {
+		if overtakes := s.overtakeLines(19, driversByNumber(v), true); len(overtakes) > 0 {
+			table += separator + "\n" + strings.Join(overtakes, "\n") + "\n"
+		}
+	}
+
 	status := separator + "\n"
 
 	s.weatherLock.Lock()
@@ -664,5 +984,5 @@ func (s *sessionBase) updateHTML(v []Messages.Timing) {
 
 	table += status
 
-	s.html = table
+	s.web.Publish(table)
 }
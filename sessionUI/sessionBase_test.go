@@ -0,0 +1,286 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"f1gopher/f1gopher-cmdline/ui"
+	"flag"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/muesli/termenv"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "Update the golden files")
+
+var sessionStart = time.Date(2023, 7, 9, 14, 0, 0, 0, time.UTC)
+
+func TestMain(m *testing.M) {
+	// Golden files are plain text so don't let the terminal running the tests add colors
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+// recordedHTML is a WebPublisher that keeps the last HTML display published
+type recordedHTML struct {
+	lock sync.Mutex
+	html string
+}
+
+func (r *recordedHTML) Publish(html string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.html = html
+}
+
+func (r *recordedHTML) String() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.html
+}
+
+func checkGolden(t *testing.T, name string, actual string) {
+	t.Helper()
+
+	golden := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(golden, []byte(actual), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(expected) != actual {
+		t.Errorf("%s does not match the golden file, run with -update to regenerate\n--- expected\n%s\n--- actual\n%s",
+			golden, expected, actual)
+	}
+}
+
+func testEvent(eventType Messages.EventType, status Messages.SessionState) Messages.Event {
+	event := Messages.Event{
+		Timestamp:       sessionStart,
+		Name:            "Fake Grand Prix",
+		Type:            eventType,
+		Status:          status,
+		CurrentLap:      12,
+		TotalLaps:       52,
+		Sector1Segments: 3,
+		Sector2Segments: 4,
+		Sector3Segments: 3,
+		TotalSegments:   10,
+		TrackStatus:     Messages.GreenFlag,
+		SafetyCar:       Messages.Clear,
+		DRSEnabled:      Messages.DRSEnabled,
+	}
+	for x := 0; x < event.TotalSegments; x++ {
+		event.SegmentFlags[x] = Messages.GreenFlag
+	}
+	event.SegmentFlags[5] = Messages.YellowFlag
+	return event
+}
+
+func testDriver(position int, number int, shortName string, color string) Messages.Timing {
+	driver := Messages.Timing{
+		Timestamp:               sessionStart,
+		Position:                position,
+		Name:                    shortName,
+		ShortName:               shortName,
+		Number:                  number,
+		HexColor:                color,
+		GapToLeader:             time.Duration(position-1) * 1500 * time.Millisecond,
+		TimeDiffToFastest:       time.Duration(position-1) * 250 * time.Millisecond,
+		TimeDiffToPositionAhead: time.Duration(position-1) * 750 * time.Millisecond,
+		Sector1:                 30*time.Second + time.Duration(position)*10*time.Millisecond,
+		Sector2:                 40*time.Second + time.Duration(position)*20*time.Millisecond,
+		Sector3:                 20*time.Second + time.Duration(position)*30*time.Millisecond,
+		FastestLap:              90*time.Second + time.Duration(position)*60*time.Millisecond,
+		Tire:                    Messages.Medium,
+		LapsOnTire:              position + 3,
+		Lap:                     12,
+		Pitstops:                1,
+		Location:                Messages.OnTrack,
+		SpeedTrap:               310 - position,
+	}
+	driver.LastLap = driver.Sector1 + driver.Sector2 + driver.Sector3
+	for x := 0; x < 10; x++ {
+		driver.Segment[x] = Messages.GreenSegment
+	}
+	driver.Segment[2] = Messages.PurpleSegment
+	driver.Segment[7] = Messages.YellowSegment
+	return driver
+}
+
+func raceScript() *fakeSession.Session {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	leader.Sector1OverallFastest = true
+	leader.OverallFastestLap = true
+	leader.DRSOpen = true
+
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	second.Sector2PersonalFastest = true
+	second.LastLapPersonalFastest = true
+
+	third := testDriver(3, 16, "LEC", "#F91536")
+	third.Tire = Messages.Hard
+	third.Location = Messages.Pitlane
+
+	stopped := testDriver(4, 4, "NOR", "#F58020")
+	stopped.Location = Messages.Stopped
+
+	data.Add(
+		testEvent(Messages.Race, Messages.Started),
+		Messages.EventTime{Timestamp: sessionStart.Add(20 * time.Minute), Remaining: 100 * time.Minute},
+		leader, second, third, stopped,
+		Messages.Weather{Timestamp: sessionStart, AirTemp: 25.5, TrackTemp: 41.25},
+		Messages.RaceControlMessage{Timestamp: sessionStart, Msg: "GREEN LIGHT - PIT EXIT OPEN", Flag: Messages.GreenFlag},
+		Messages.RaceControlMessage{Timestamp: sessionStart.Add(5 * time.Minute), Msg: "YELLOW IN TRACK SECTOR 6", Flag: Messages.YellowFlag},
+		Messages.Radio{Timestamp: sessionStart, Driver: "HAM", Msg: []byte("not an mp3")})
+
+	// The gap to the car in front shrinks for HAM over a few updates
+	for x := 1; x <= 3; x++ {
+		second.TimeDiffToPositionAhead -= 100 * time.Millisecond
+		data.Add(second)
+	}
+
+	return data
+}
+
+func qualifyingScript() *fakeSession.Session {
+	data := fakeSession.New(Messages.QualifyingSession, "Fake Grand Prix", sessionStart)
+
+	data.Add(testEvent(Messages.Qualifying2, Messages.Started),
+		Messages.EventTime{Timestamp: sessionStart.Add(5 * time.Minute), Remaining: 10 * time.Minute})
+
+	for x := 1; x <= 15; x++ {
+		driver := testDriver(x, x+20, "D"+string(rune('A'+x-1))+"X", "#FFFFFF")
+		driver.KnockedOutOfQualifying = x == 15
+		data.Add(driver)
+	}
+
+	data.Add(
+		Messages.Weather{Timestamp: sessionStart, AirTemp: 19, TrackTemp: 28, Rainfall: true},
+		Messages.RaceControlMessage{Timestamp: sessionStart, Msg: "CHEQUERED FLAG", Flag: Messages.ChequeredFlag})
+
+	return data
+}
+
+// playSession enters the session, plays the scripted messages and renders the first frame, which resets the
+// session bests. The session is left when the test finishes.
+func playSession(t *testing.T, session SessionUI, data *fakeSession.Session) {
+	t.Helper()
+
+	session.Enter(data, ui.Replay, false)
+	t.Cleanup(session.Leave)
+	data.Play()
+	session.View()
+}
+
+// playRace is playSession for a race displayed with the settings
+func playRace(t *testing.T, data *fakeSession.Session, settings Settings) (*raceUI, *recordedHTML) {
+	t.Helper()
+
+	web := &recordedHTML{}
+	session := NewRaceUI(web, settings)
+	playSession(t, session, data)
+	return session, web
+}
+
+// raceColumns is the default settings showing only the named race columns
+func raceColumns(names ...string) Settings {
+	settings := NewSettings()
+	settings.Layouts.Race = columnSettings(names...)
+	return settings
+}
+
+func TestRaceUI(t *testing.T) {
+	session, web := playRace(t, raceScript(), NewSettings())
+	checkGolden(t, "race", session.View())
+	checkGolden(t, "race_html", web.String())
+
+	// Toggling to gap to leader changes the gap column
+	session.Update(keyMsg("t"))
+	checkGolden(t, "race_gap_to_leader", session.View())
+
+	// Narrow terminals lose columns and short ones lose race control messages
+	session.Resize(tea.WindowSizeMsg{Width: 120, Height: 40})
+	checkGolden(t, "race_120", session.View())
+	session.Resize(tea.WindowSizeMsg{Width: 80, Height: 13})
+	checkGolden(t, "race_80", session.View())
+
+	// The web page is not limited by the terminal size
+	checkGolden(t, "race_html_gap_to_leader", web.String())
+}
+
+func TestPracticeQualifyingUI(t *testing.T) {
+	data := qualifyingScript()
+
+	web := &recordedHTML{}
+	session := NewPracticeQualifyingUI(web, NewSettings())
+	playSession(t, session, data)
+	checkGolden(t, "qualifying", session.View())
+	checkGolden(t, "qualifying_html", web.String())
+}
+
+func keyMsg(key string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+func TestSessionControls(t *testing.T) {
+	data := raceScript()
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+
+	session.Update(tea.KeyMsg{Type: tea.KeyUp})
+	session.Update(tea.KeyMsg{Type: tea.KeyCtrlCloseBracket})
+	session.Update(tea.KeyMsg{Type: tea.KeyRight})
+	session.Update(keyMsg("p"))
+	session.Update(keyMsg("s"))
+	session.Update(keyMsg("r"))
+
+	if increments := data.TimeIncrements(); len(increments) != 2 || increments[0] != time.Minute || increments[1] != 5*time.Second {
+		t.Errorf("unexpected time increments: %v", increments)
+	}
+	if data.LapIncrements() != 1 {
+		t.Errorf("expected one lap increment, got %d", data.LapIncrements())
+	}
+	if !data.IsPaused() {
+		t.Error("expected the session to be paused")
+	}
+	if data.SessionStartSkips() != 1 {
+		t.Errorf("expected one skip to session start, got %d", data.SessionStartSkips())
+	}
+	if !session.isMuted.Load() {
+		t.Error("expected the radio to be muted")
+	}
+
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.MainMenu {
+		t.Errorf("expected escape to return to the main menu, got %v", page)
+	}
+}
@@ -0,0 +1,322 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var racePoints = []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+var sprintPoints = []float64{8, 7, 6, 5, 4, 3, 2, 1}
+
+// The first sprints in 2021 only scored for the top three
+var sprintPoints2021 = []float64{3, 2, 1}
+
+// Seasons where the driver with the fastest lap scored a point if they finished in the top ten
+const firstFastestLapYear = 2019
+const lastFastestLapYear = 2024
+
+// StandingsEntry is the points for a driver or constructor before the race. Drivers are matched to the timing by
+// number and constructors by name.
+type StandingsEntry struct {
+	Number int     `json:"number,omitempty"`
+	Name   string  `json:"name"`
+	Points float64 `json:"points"`
+}
+
+// Standings is the drivers' and constructors' championships before the race
+type Standings struct {
+	Drivers      []StandingsEntry `json:"drivers"`
+	Constructors []StandingsEntry `json:"constructors"`
+}
+
+func NewStandings() *Standings {
+	return &Standings{}
+}
+
+// LoadStandings reads the standings from a JSON file or from a CSV file if the path ends in .csv. Empty standings
+// are used if the file doesn't exist.
+func LoadStandings(path string) (*Standings, error) {
+	standings := NewStandings()
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return standings, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		err = standings.readCSV(string(data))
+	} else {
+		err = json.Unmarshal(data, standings)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid standings file %s: %v", path, err)
+	}
+
+	for _, driver := range standings.Drivers {
+		if driver.Number <= 0 {
+			return nil, fmt.Errorf("invalid standings file %s: driver \"%s\" has no number", path, driver.Name)
+		}
+	}
+	for _, constructor := range standings.Constructors {
+		if len(constructor.Name) == 0 {
+			return nil, fmt.Errorf("invalid standings file %s: constructor has no name", path)
+		}
+	}
+
+	return standings, nil
+}
+
+// readCSV reads rows of type, number, name and points where type is driver or constructor. The first row is a
+// header and the number is left empty for constructors.
+func (s *Standings) readCSV(data string) error {
+	records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+	if err != nil {
+		return err
+	}
+
+	for x, record := range records {
+		if x == 0 {
+			continue
+		}
+		if len(record) != 4 {
+			return fmt.Errorf("line %d: expected type, number, name and points", x+1)
+		}
+
+		points, err := strconv.ParseFloat(strings.TrimSpace(record[3]), 64)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid points \"%s\"", x+1, record[3])
+		}
+		entry := StandingsEntry{Name: strings.TrimSpace(record[2]), Points: points}
+
+		switch strings.ToLower(strings.TrimSpace(record[0])) {
+		case "driver":
+			entry.Number, err = strconv.Atoi(strings.TrimSpace(record[1]))
+			if err != nil {
+				return fmt.Errorf("line %d: invalid number \"%s\"", x+1, record[1])
+			}
+			s.Drivers = append(s.Drivers, entry)
+		case "constructor":
+			s.Constructors = append(s.Constructors, entry)
+		default:
+			return fmt.Errorf("line %d: unknown type \"%s\"", x+1, record[0])
+		}
+	}
+
+	return nil
+}
+
+// points is what a driver would score finishing in position. Drivers who have stopped don't score.
+func points(session Messages.SessionType, year int, driver Messages.Timing) float64 {
+	if driver.Location == Messages.Stopped || driver.Location == Messages.OutOfRace || driver.Position <= 0 {
+		return 0
+	}
+
+	var table []float64
+	switch session {
+	case Messages.RaceSession:
+		table = racePoints
+	case Messages.SprintSession:
+		table = sprintPoints
+		if year == 2021 {
+			table = sprintPoints2021
+		}
+	default:
+		return 0
+	}
+
+	if driver.Position > len(table) {
+		return 0
+	}
+
+	result := table[driver.Position-1]
+	if session == Messages.RaceSession && driver.OverallFastestLap &&
+		year >= firstFastestLapYear && year <= lastFastestLapYear {
+		result++
+	}
+	return result
+}
+
+// standingsRow is a driver or constructor in the standings with the points they would gain if the race finished now
+type standingsRow struct {
+	name   string
+	color  string
+	before float64
+	gained float64
+	// Championship positions before the race and as it stands
+	previous int
+	position int
+}
+
+func (r standingsRow) total() float64 {
+	return r.before + r.gained
+}
+
+// rankStandings works out the position of each row before the race and with the points gained and returns them in
+// the new order. Ties keep the order from before the race.
+func rankStandings(rows []standingsRow) []standingsRow {
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].before > rows[j].before
+	})
+	for x := range rows {
+		rows[x].previous = x + 1
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].total() > rows[j].total()
+	})
+	for x := range rows {
+		rows[x].position = x + 1
+	}
+	return rows
+}
+
+// projectStandings combines the standings before the race with the points each driver and team would score if the
+// race finished now. Drivers and teams that aren't in the standings start from zero.
+func projectStandings(standings *Standings, drivers []Messages.Timing, session Messages.SessionType, year int) (
+	[]standingsRow, []standingsRow) {
+
+	driverRows := make([]standingsRow, 0, len(standings.Drivers))
+	byNumber := make(map[int]int)
+	for _, entry := range standings.Drivers {
+		byNumber[entry.Number] = len(driverRows)
+		driverRows = append(driverRows, standingsRow{name: entry.Name, before: entry.Points})
+	}
+
+	constructorRows := make([]standingsRow, 0, len(standings.Constructors))
+	byTeam := make(map[string]int)
+	for _, entry := range standings.Constructors {
+		byTeam[strings.ToLower(entry.Name)] = len(constructorRows)
+		constructorRows = append(constructorRows, standingsRow{name: entry.Name, before: entry.Points})
+	}
+
+	for _, driver := range drivers {
+		scored := points(session, year, driver)
+
+		x, exists := byNumber[driver.Number]
+		if !exists {
+			x = len(driverRows)
+			byNumber[driver.Number] = x
+			driverRows = append(driverRows, standingsRow{name: driver.ShortName})
+		}
+		driverRows[x].color = driver.HexColor
+		driverRows[x].gained += scored
+
+		if len(driver.Team) == 0 {
+			continue
+		}
+		x, exists = byTeam[strings.ToLower(driver.Team)]
+		if !exists {
+			x = len(constructorRows)
+			byTeam[strings.ToLower(driver.Team)] = x
+			constructorRows = append(constructorRows, standingsRow{name: driver.Team})
+		}
+		constructorRows[x].color = driver.HexColor
+		constructorRows[x].gained += scored
+	}
+
+	return rankStandings(driverRows), rankStandings(constructorRows)
+}
+
+func fmtPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// positionChangeCell is the number of places moved up or down the championship
+func positionChangeCell(row standingsRow) cell {
+	switch {
+	case row.position < row.previous:
+		return text(fmt.Sprintf("▲%d", row.previous-row.position), "#00FF00")
+	case row.position > row.previous:
+		return text(fmt.Sprintf("▼%d", row.position-row.previous), "#FF0000")
+	default:
+		return text("-", "")
+	}
+}
+
+// standingsLines is the table for one of the championships
+func (s *sessionBase) standingsLines(title string, rows []standingsRow, html bool) []string {
+	columns := []layoutColumn{
+		{column: &column{header: "Pos"}, width: 5},
+		{column: &column{header: "+/-"}, width: 5},
+		{column: &column{header: title}, width: 26},
+		{column: &column{header: "Before"}, width: 8},
+		{column: &column{header: "Race"}, width: 6},
+		{column: &column{header: "Total"}, width: 8},
+	}
+
+	separator := columnSeparator(columns)
+	lines := []string{s.renderHeader(columns), separator}
+	for _, row := range rows {
+		gained := text("", "")
+		if row.gained > 0 {
+			gained = text("+"+fmtPoints(row.gained), "#00FF00")
+		}
+
+		lines = append(lines, renderCells(columns, []cell{
+			text(fmt.Sprintf("%d", row.position), ""),
+			positionChangeCell(row),
+			text(row.name, row.color),
+			text(fmtPoints(row.before), ""),
+			gained,
+			text(fmtPoints(row.total()), ""),
+		}, html))
+	}
+	return append(lines, separator)
+}
+
+// standingsView is the drivers' and constructors' championships if the race finished now for the terminal or the
+// web server
+func (s *sessionBase) standingsView(html bool) string {
+	s.eventLock.Lock()
+	currentLap := s.event.CurrentLap
+	totalLaps := s.event.TotalLaps
+	s.eventLock.Unlock()
+
+	standings := s.standings
+	if standings == nil {
+		standings = NewStandings()
+	}
+	drivers, constructors := projectStandings(standings, s.sortedDrivers(), s.f.Session(), s.f.SessionStart().Year())
+
+	lines := []string{fmt.Sprintf("Championship Standings If The %s Finished Now - Lap %d/%d", s.f.Session().String(),
+		currentLap, totalLaps)}
+	if len(standings.Drivers) == 0 && len(standings.Constructors) == 0 {
+		lines = append(lines, renderSpans(text("No standings from before the race so only the points from this race are shown", "#FFFF00"), html))
+	}
+	lines = append(lines, s.standingsLines("Driver", drivers, html)...)
+	lines = append(lines, "")
+	lines = append(lines, s.standingsLines("Constructor", constructors, html)...)
+
+	if !html {
+		lines = s.scrollPage(lines, 1)
+		lines = append(lines, "Up/Down: scroll, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
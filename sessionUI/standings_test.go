@@ -0,0 +1,136 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/fakeSession"
+	"f1gopher/f1gopher-cmdline/ui"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadStandings(t *testing.T) {
+	dir := t.TempDir()
+
+	standings, err := LoadStandings(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(standings.Drivers) != 0 || len(standings.Constructors) != 0 {
+		t.Errorf("expected empty standings, got %+v", standings)
+	}
+
+	path := filepath.Join(dir, "standings.json")
+	os.WriteFile(path, []byte(`{"drivers":[{"number":1,"name":"VER","points":255.5}],"constructors":[{"name":"Red Bull Racing","points":411}]}`), 0644)
+	standings, err = LoadStandings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(standings.Drivers) != 1 || standings.Drivers[0].Points != 255.5 || standings.Constructors[0].Name != "Red Bull Racing" {
+		t.Errorf("unexpected standings: %+v", standings)
+	}
+
+	path = filepath.Join(dir, "standings.csv")
+	os.WriteFile(path, []byte("type,number,name,points\ndriver,44,HAM,180\nconstructor,,Mercedes,289\n"), 0644)
+	standings, err = LoadStandings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(standings.Drivers) != 1 || standings.Drivers[0].Number != 44 || standings.Constructors[0].Points != 289 {
+		t.Errorf("unexpected standings: %+v", standings)
+	}
+
+	os.WriteFile(path, []byte("type,number,name,points\nteam,,Mercedes,289\n"), 0644)
+	if _, err = LoadStandings(path); err == nil {
+		t.Error("expected an error for an unknown type")
+	}
+}
+
+func TestPoints(t *testing.T) {
+	driver := func(position int, fastestLap bool, location Messages.CarLocation) Messages.Timing {
+		return Messages.Timing{Position: position, OverallFastestLap: fastestLap, Location: location}
+	}
+
+	tests := []struct {
+		name     string
+		session  Messages.SessionType
+		year     int
+		driver   Messages.Timing
+		expected float64
+	}{
+		{"race win", Messages.RaceSession, 2023, driver(1, false, Messages.OnTrack), 25},
+		{"fastest lap", Messages.RaceSession, 2023, driver(10, true, Messages.OnTrack), 2},
+		{"fastest lap outside the points", Messages.RaceSession, 2023, driver(11, true, Messages.OnTrack), 0},
+		{"fastest lap before the bonus", Messages.RaceSession, 2018, driver(1, true, Messages.OnTrack), 25},
+		{"sprint", Messages.SprintSession, 2023, driver(8, true, Messages.OnTrack), 1},
+		{"first sprints", Messages.SprintSession, 2021, driver(4, false, Messages.OnTrack), 0},
+		{"stopped", Messages.RaceSession, 2023, driver(3, false, Messages.Stopped), 0},
+	}
+
+	for _, test := range tests {
+		if value := points(test.session, test.year, test.driver); value != test.expected {
+			t.Errorf("%s: expected %v points, got %v", test.name, test.expected, value)
+		}
+	}
+}
+
+func TestStandings(t *testing.T) {
+	data := fakeSession.New(Messages.RaceSession, "Fake Grand Prix", sessionStart)
+	data.Add(testEvent(Messages.Race, Messages.Started))
+
+	leader := testDriver(1, 1, "VER", "#3671C6")
+	leader.Team = "Red Bull Racing"
+	leader.OverallFastestLap = true
+	second := testDriver(2, 44, "HAM", "#6CD3BF")
+	second.Team = "Mercedes"
+	third := testDriver(3, 81, "PIA", "#F58020")
+	third.Team = "McLaren"
+	stopped := testDriver(4, 16, "LEC", "#F91536")
+	stopped.Team = "Ferrari"
+	stopped.Location = Messages.Stopped
+	data.Add(leader, second, third, stopped)
+
+	// VER overtakes HAM in the championship and PIA scores for a team that isn't in the standings
+	standings := &Standings{
+		Drivers: []StandingsEntry{
+			{Number: 44, Name: "Lewis Hamilton", Points: 110},
+			{Number: 1, Name: "Max Verstappen", Points: 105},
+			{Number: 16, Name: "Charles Leclerc", Points: 50},
+		},
+		Constructors: []StandingsEntry{
+			{Name: "Mercedes", Points: 180},
+			{Name: "Red Bull Racing", Points: 175},
+			{Name: "Ferrari", Points: 90},
+		},
+	}
+
+	web := &recordedHTML{}
+	settings := NewSettings()
+	settings.Standings = standings
+	session := NewRaceUI(web, settings)
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+	data.Play()
+	session.View()
+
+	if page, _ := session.Update(keyMsg("w")); page != ui.Standings {
+		t.Fatalf("expected the standings, got %v", page)
+	}
+	checkGolden(t, "standings", session.View())
+	checkGolden(t, "standings_html", web.String())
+}
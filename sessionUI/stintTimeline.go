@@ -0,0 +1,123 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"fmt"
+	"strings"
+)
+
+// stintBar draws the stints scaled so the race distance fills the width. Each stint starts with the first letter of
+// the compound and how many laps it lasted so consecutive stints on the same compound can be told apart, laps still
+// to run are dotted.
+func stintBar(stints []history.Stint, totalLaps int, width int) cell {
+	column := func(lap int) int {
+		return min(width, lap*width/totalLaps)
+	}
+
+	var bar cell
+	used := 0
+	for _, stint := range stints {
+		start := max(used, column(stint.StartLap-1))
+		if start > used {
+			bar = append(bar, span{text: strings.Repeat(" ", start-used)})
+		}
+
+		// Every stint is wide enough for its label however short it was, apart from at the end of the bar
+		label := fmt.Sprintf("%s%d", tireLetter(stint.Tire.String()), stint.Laps())
+		end := min(width, max(start+len(label), column(min(stint.EndLap, totalLaps))))
+		if end <= start {
+			break
+		}
+
+		label = label[:min(len(label), end-start)]
+		bar = append(bar, span{text: label + strings.Repeat("█", end-start-len(label)), color: tireColor(stint.Tire)})
+		used = end
+	}
+
+	if used < width {
+		bar = append(bar, span{text: strings.Repeat("·", width-used), color: "#808080"})
+	}
+	return bar
+}
+
+func tireLetter(tire string) string {
+	if len(tire) == 0 {
+		return "?"
+	}
+	return tire[:1]
+}
+
+// stintTimelineView displays the stints for every driver in position order for the terminal or the web server
+func (s *sessionBase) stintTimelineView(html bool) string {
+	drivers := s.sortedDrivers()
+
+	s.eventLock.Lock()
+	currentLap := s.event.CurrentLap
+	raceDistance := s.event.TotalLaps
+	s.eventLock.Unlock()
+
+	// Without a race distance the laps done so far fill the width
+	totalLaps := raceDistance
+	for _, driver := range drivers {
+		totalLaps = max(totalLaps, driver.Lap+1)
+	}
+	totalLaps = max(1, totalLaps)
+
+	columns := []layoutColumn{
+		{column: &column{header: "Pos"}, width: 5},
+		{column: &column{header: "Driver"}, width: 8},
+		{column: &column{header: "Stints", fill: true}, width: 60},
+		{column: &column{header: "Pit Laps"}, width: 16},
+	}
+	if !html && s.currentWidth > 0 {
+		columns[2].width = max(10, s.currentWidth-tableWidth(columns)+columns[2].width)
+	}
+
+	separator := columnSeparator(columns)
+	lines := []string{
+		fmt.Sprintf("Stint Timeline - Lap %d/%d", currentLap, raceDistance),
+		s.renderHeader(columns),
+		separator,
+	}
+
+	for _, driver := range drivers {
+		var pitLaps []string
+		for _, pitStop := range driver.PitStopTimes {
+			pitLaps = append(pitLaps, fmt.Sprintf("%d", pitStop.Lap))
+		}
+
+		lines = append(lines, renderCells(columns, []cell{
+			text(fmt.Sprintf("%d", driver.Position), ""),
+			text(driver.ShortName, driver.HexColor),
+			stintBar(s.history.CurrentStints(driver), totalLaps, columns[2].width),
+			text(strings.Join(pitLaps, ", "), ""),
+		}, html))
+	}
+
+	if len(drivers) == 0 {
+		lines = append(lines, "No timing")
+	}
+	lines = append(lines, separator)
+
+	if !html {
+		lines = s.scrollPage(lines, 3)
+		lines = append(lines, "Up/Down: scroll, Esc: back to the timing tower")
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,57 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"f1gopher/f1gopher-cmdline/history"
+	"f1gopher/f1gopher-cmdline/ui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"testing"
+)
+
+func TestStintTimeline(t *testing.T) {
+	session, web := playRace(t, lapsScript(), NewSettings())
+	session.Resize(tea.WindowSizeMsg{Width: 80, Height: 20})
+
+	if page, _ := session.Update(keyMsg("l")); page != ui.StintTimeline {
+		t.Fatalf("expected the stint timeline, got %v", page)
+	}
+	checkGolden(t, "stint_timeline", session.View())
+	checkGolden(t, "stint_timeline_html", web.String())
+
+	if page, _ := session.Update(tea.KeyMsg{Type: tea.KeyEsc}); page != ui.Replay {
+		t.Fatalf("expected escape to return to the tower, got %v", page)
+	}
+}
+
+func TestStintBar(t *testing.T) {
+	stints := []history.Stint{
+		{Tire: Messages.Medium, StartLap: 1, EndLap: 12},
+		{Tire: Messages.Hard, StartLap: 13, EndLap: 13},
+		{Tire: Messages.Soft, StartLap: 14, EndLap: 20},
+	}
+
+	// The one lap stint is widened to fit its label
+	if bar := renderSpans(stintBar(stints, 40, 20), false); bar != "M12███H1S7··········" {
+		t.Errorf("unexpected stint bar: %s", bar)
+	}
+
+	// Labels are cut off at the end of the bar
+	if bar := renderSpans(stintBar(stints, 20, 7), false); bar != "M12█H1S" {
+		t.Errorf("unexpected stint bar: %s", bar)
+	}
+}
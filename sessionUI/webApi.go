@@ -0,0 +1,189 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/json"
+	"f1gopher/f1gopher-cmdline/history"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"github.com/gorilla/mux"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// All durations in the JSON API are in nanoseconds, the same as time.Duration
+
+type apiSession struct {
+	Name                  string
+	Session               string
+	Track                 string
+	CircuitTimezone       string
+	SessionStart          time.Time
+	EventTime             time.Time
+	RemainingTime         time.Duration
+	IsPaused              bool
+	FastestSector1        time.Duration
+	FastestSector2        time.Duration
+	FastestSector3        time.Duration
+	TheoreticalFastestLap time.Duration
+	FastestSpeedTrap      int
+	// Number of the driver who set each of the fastest sectors
+	FastestSectorDrivers [3]int
+	// Lap made from each driver's best sectors by driver number
+	IdealLaps map[int]time.Duration
+
+	// Slope of the gap to the car in front in milliseconds per update, by driver number. Positive means the gap
+	// is growing. Only calculated for races and sprints.
+	GapTrends map[int]int64
+}
+
+// createWebHandler creates the routes for the session specific part of the web server
+func (s *sessionBase) createWebHandler() http.Handler {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/control/{action}", s.controlHandler).Methods(http.MethodPost)
+
+	api := router.PathPrefix("/api").Methods(http.MethodGet).Subrouter()
+
+	api.HandleFunc("/timing", s.apiHandler(func() any {
+		s.dataLock.Lock()
+		defer s.dataLock.Unlock()
+
+		timing := make(map[int]Messages.Timing, len(s.data))
+		for number, driver := range s.data {
+			timing[number] = driver
+		}
+		return timing
+	}))
+
+	api.HandleFunc("/event", s.apiHandler(func() any {
+		s.eventLock.Lock()
+		defer s.eventLock.Unlock()
+
+		return s.event
+	}))
+
+	api.HandleFunc("/racecontrol", s.apiHandler(func() any {
+		s.rcMessagesLock.Lock()
+		defer s.rcMessagesLock.Unlock()
+
+		return append([]Messages.RaceControlMessage{}, s.rcMessages...)
+	}))
+
+	api.HandleFunc("/weather", s.apiHandler(func() any {
+		s.weatherLock.Lock()
+		defer s.weatherLock.Unlock()
+
+		return s.weather
+	}))
+
+	api.HandleFunc("/session", s.apiHandler(func() any {
+		return s.sessionSummary()
+	}))
+
+	api.HandleFunc("/history", s.apiHandler(func() any {
+		return s.history.All()
+	}))
+
+	api.HandleFunc("/history/{driver:[0-9]+}", s.apiRequestHandler(func(r *http.Request) any {
+		driver, _ := strconv.Atoi(mux.Vars(r)["driver"])
+		return append([]history.Lap{}, s.history.Laps(driver)...)
+	}))
+
+	api.HandleFunc("/history.csv", func(w http.ResponseWriter, r *http.Request) {
+		s.sourceLock.RLock()
+		defer s.sourceLock.RUnlock()
+
+		if s.f == nil {
+			http.Error(w, "No active session", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Cache-Control", "no-cache")
+		s.history.WriteCSV(w)
+	})
+
+	return router
+}
+
+// apiHandler writes the value returned by content as JSON, or a 404 if there is no session being displayed
+func (s *sessionBase) apiHandler(content func() any) http.HandlerFunc {
+	return s.apiRequestHandler(func(r *http.Request) any {
+		return content()
+	})
+}
+
+// apiRequestHandler is the same as apiHandler for content that depends on the request
+func (s *sessionBase) apiRequestHandler(content func(r *http.Request) any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.sourceLock.RLock()
+		defer s.sourceLock.RUnlock()
+
+		if s.f == nil {
+			http.Error(w, "No active session", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-cache")
+		json.NewEncoder(w).Encode(content(r))
+	}
+}
+
+// sessionSummary must be called with sourceLock held
+func (s *sessionBase) sessionSummary() apiSession {
+	summary := apiSession{
+		Name:         s.f.Name(),
+		Session:      s.f.Session().String(),
+		Track:        s.f.Track(),
+		SessionStart: s.f.SessionStart(),
+		IsPaused:     s.isPaused(),
+		IdealLaps:    make(map[int]time.Duration),
+		GapTrends:    make(map[int]int64),
+	}
+
+	s.eventLock.Lock()
+	summary.EventTime = s.eventTime
+	summary.RemainingTime = s.remainingTime
+	s.eventLock.Unlock()
+
+	if timezone := s.f.CircuitTimezone(); timezone != nil {
+		summary.CircuitTimezone = timezone.String()
+	}
+
+	s.fastestLock.Lock()
+	summary.FastestSector1 = s.fastestSector1
+	summary.FastestSector2 = s.fastestSector2
+	summary.FastestSector3 = s.fastestSector3
+	summary.TheoreticalFastestLap = s.theoreticalFastestLap
+	summary.FastestSpeedTrap = s.fastestSpeedTrap
+	summary.FastestSectorDrivers = s.fastestSectorDrivers
+	for number, best := range s.driverBests {
+		if ideal := best.ideal(); ideal > 0 {
+			summary.IdealLaps[number] = ideal
+		}
+	}
+	s.fastestLock.Unlock()
+
+	s.driverGapLock.Lock()
+	for number, trend := range s.driverGapTrend {
+		summary.GapTrends[number] = trend.trend
+	}
+	s.driverGapLock.Unlock()
+
+	return summary
+}
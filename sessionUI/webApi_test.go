@@ -0,0 +1,158 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package sessionUI
+
+import (
+	"encoding/json"
+	"f1gopher/f1gopher-cmdline/history"
+	"f1gopher/f1gopher-cmdline/ui"
+	"github.com/f1gopher/f1gopherlib/Messages"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func getJSON(t *testing.T, router http.Handler, path string, result any) int {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), result); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+	}
+	return recorder.Code
+}
+
+func TestApi(t *testing.T) {
+	data := raceScript()
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	router := session.WebHandler()
+
+	if code := getJSON(t, router, "/api/session", nil); code != http.StatusNotFound {
+		t.Errorf("expected not found without a session, got %d", code)
+	}
+
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+	data.Play()
+	session.View()
+	session.View()
+
+	var timing map[int]Messages.Timing
+	getJSON(t, router, "/api/timing", &timing)
+	if len(timing) != 4 || timing[44].ShortName != "HAM" || timing[44].Position != 2 {
+		t.Errorf("unexpected timing: %v", timing)
+	}
+
+	var event Messages.Event
+	getJSON(t, router, "/api/event", &event)
+	if event.Type != Messages.Race || event.CurrentLap != 12 {
+		t.Errorf("unexpected event: %v", event)
+	}
+
+	var rcMessages []Messages.RaceControlMessage
+	getJSON(t, router, "/api/racecontrol", &rcMessages)
+	if len(rcMessages) != 2 || rcMessages[1].Flag != Messages.YellowFlag {
+		t.Errorf("unexpected race control messages: %v", rcMessages)
+	}
+
+	var weather Messages.Weather
+	getJSON(t, router, "/api/weather", &weather)
+	if weather.TrackTemp != 41.25 {
+		t.Errorf("unexpected weather: %v", weather)
+	}
+
+	var laps []history.Lap
+	getJSON(t, router, "/api/history/44", &laps)
+	if len(laps) != 1 || laps[0].Number != 12 || laps[0].Position != 2 {
+		t.Errorf("unexpected history: %v", laps)
+	}
+
+	var summary apiSession
+	getJSON(t, router, "/api/session", &summary)
+	if summary.Session != "Race" || summary.FastestSector1 != 30010*time.Millisecond ||
+		summary.TheoreticalFastestLap != 90060*time.Millisecond || summary.FastestSpeedTrap != 309 {
+		t.Errorf("unexpected session: %+v", summary)
+	}
+	if summary.FastestSectorDrivers[0] != 1 || summary.IdealLaps[44] != 90120*time.Millisecond {
+		t.Errorf("unexpected session bests: %+v", summary)
+	}
+	if summary.GapTrends[44] >= 0 {
+		t.Errorf("expected the gap trend for HAM to be shrinking, got %d", summary.GapTrends[44])
+	}
+}
+
+func TestControlApi(t *testing.T) {
+	data := raceScript()
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	session.Enter(data, ui.Replay, false)
+	defer session.Leave()
+
+	post := func(action string) int {
+		recorder := httptest.NewRecorder()
+		session.WebHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/control/"+action, nil))
+		return recorder.Code
+	}
+
+	for _, action := range []string{"skip-5s", "skip-1m", "skip-lap", "session-start", "mute", "gap", "pause"} {
+		if code := post(action); code != http.StatusNoContent {
+			t.Errorf("%s: unexpected status %d", action, code)
+		}
+	}
+
+	if code := post("rewind"); code != http.StatusNotFound {
+		t.Errorf("expected unknown control to be not found, got %d", code)
+	}
+
+	if !data.IsPaused() || data.LapIncrements() != 1 || data.SessionStartSkips() != 1 || len(data.TimeIncrements()) != 2 {
+		t.Error("expected the controls to be passed to the session data")
+	}
+	if !session.isMuted.Load() || session.gapToInfront.Load() {
+		t.Error("expected the radio to be muted and the gap to be to the leader")
+	}
+}
+
+func TestControlWhileLeaving(t *testing.T) {
+	data := raceScript()
+
+	session := NewRaceUI(&recordedHTML{}, NewSettings())
+	session.Enter(data, ui.Replay, false)
+
+	codes := make(chan int)
+	go func() {
+		defer close(codes)
+		for x := 0; x < 100; x++ {
+			recorder := httptest.NewRecorder()
+			session.WebHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/control/skip-lap", nil))
+			codes <- recorder.Code
+		}
+	}()
+
+	<-codes
+	session.Leave()
+
+	for code := range codes {
+		if code != http.StatusNoContent && code != http.StatusNotFound {
+			t.Errorf("unexpected status %d", code)
+		}
+	}
+}
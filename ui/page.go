@@ -23,4 +23,12 @@ const (
 	Live
 	Replay
 	Quit
+	DriverDetail
+	Comparison
+	GapChart
+	StintTimeline
+	PaceAnalysis
+	Standings
+	Bookmarks
+	CacheMenu
 )
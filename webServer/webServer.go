@@ -0,0 +1,333 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package webServer
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/gorilla/mux"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const NoSessionHTML = "No session is currently being displayed"
+
+// Server mirrors whichever session is currently being displayed. It lives for the whole lifetime of the
+// application and sessions publish their display to it and provide the handler for their API.
+type Server struct {
+	addresses    []string
+	controlToken string
+	servers      []*http.Server
+	router       *mux.Router
+	wg           sync.WaitGroup
+	done         chan struct{}
+
+	html        string
+	htmlLock    sync.Mutex
+	subscribers map[chan string]struct{}
+
+	session     http.Handler
+	sessionLock sync.Mutex
+}
+
+// New creates a server for the addresses. The playback controls can only be used by requests providing the
+// controlToken and are disabled if it is empty.
+func New(addresses []string, controlToken string) *Server {
+	s := &Server{
+		addresses:    addresses,
+		controlToken: controlToken,
+		done:         make(chan struct{}),
+		html:         NoSessionHTML,
+		subscribers:  make(map[chan string]struct{}),
+	}
+
+	s.router = mux.NewRouter()
+	s.router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(page))
+	})
+	s.router.HandleFunc("/data", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(s.currentHTML()))
+	})
+	s.router.HandleFunc("/events", s.streamHTML)
+	s.router.PathPrefix("/api/").HandlerFunc(s.sessionHandler)
+
+	return s
+}
+
+func (s *Server) Addresses() []string {
+	return s.addresses
+}
+
+// Start listens on all the addresses and returns an error for each one that couldn't be bound. The server
+// still runs on any addresses that did bind.
+func (s *Server) Start() []error {
+	var errs []error
+
+	for _, address := range s.addresses {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		srv := &http.Server{
+			Handler:      s.router,
+			Addr:         address,
+			WriteTimeout: 15 * time.Second,
+			ReadTimeout:  15 * time.Second,
+		}
+		s.servers = append(s.servers, srv)
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			srv.Serve(listener)
+		}()
+	}
+
+	return errs
+}
+
+// Shutdown closes any streaming clients and waits for the in progress requests to finish
+func (s *Server) Shutdown() {
+	close(s.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for _, srv := range s.servers {
+		srv.Shutdown(ctx)
+	}
+	s.wg.Wait()
+}
+
+// SetSession changes the session whose API is served. Passing nil means no session is being displayed.
+func (s *Server) SetSession(handler http.Handler) {
+	s.sessionLock.Lock()
+	s.session = handler
+	s.sessionLock.Unlock()
+
+	if handler == nil {
+		s.Publish(NoSessionHTML)
+	}
+}
+
+func (s *Server) sessionHandler(w http.ResponseWriter, r *http.Request) {
+	s.sessionLock.Lock()
+	handler := s.session
+	s.sessionLock.Unlock()
+
+	if strings.HasPrefix(r.URL.Path, "/api/control/") && !s.authorized(w, r) {
+		return
+	}
+
+	if handler == nil {
+		http.Error(w, "No active session", http.StatusNotFound)
+		return
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// authorized checks the request has the bearer token needed to use the remote controls
+func (s *Server) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if len(s.controlToken) == 0 {
+		http.Error(w, "Remote control is disabled", http.StatusForbidden)
+		return false
+	}
+
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.controlToken)) != 1 {
+		http.Error(w, "Invalid token", http.StatusUnauthorized)
+		return false
+	}
+
+	return true
+}
+
+// Publish stores the latest HTML display and pushes it to any streaming clients if it has changed. Slow clients
+// only ever get the most recent frame rather than a backlog.
+func (s *Server) Publish(html string) {
+	s.htmlLock.Lock()
+	defer s.htmlLock.Unlock()
+
+	if html == s.html {
+		return
+	}
+	s.html = html
+
+	for updates := range s.subscribers {
+		select {
+		case <-updates:
+		default:
+		}
+		updates <- html
+	}
+}
+
+func (s *Server) currentHTML() string {
+	s.htmlLock.Lock()
+	defer s.htmlLock.Unlock()
+
+	return s.html
+}
+
+func (s *Server) subscribe() chan string {
+	s.htmlLock.Lock()
+	defer s.htmlLock.Unlock()
+
+	updates := make(chan string, 1)
+	s.subscribers[updates] = struct{}{}
+	return updates
+}
+
+func (s *Server) unsubscribe(updates chan string) {
+	s.htmlLock.Lock()
+	defer s.htmlLock.Unlock()
+
+	delete(s.subscribers, updates)
+}
+
+// streamHTML is a Server-Sent Events endpoint that pushes the HTML display every time it changes
+func (s *Server) streamHTML(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	// The stream stays open for as long as the client wants it so ignore the servers write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	updates := s.subscribe()
+	defer s.unsubscribe(updates)
+
+	writeFrame(w, s.currentHTML())
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.done:
+			return
+
+		case html := <-updates:
+			writeFrame(w, html)
+			flusher.Flush()
+		}
+	}
+}
+
+// writeFrame writes a single SSE message. Each line needs its own data field and the browser rejoins them
+// with newlines.
+func writeFrame(w http.ResponseWriter, html string) {
+	for _, line := range strings.Split(html, "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+const page = `<html>
+<title>GopherF1</title>
+<head>    
+	<meta charset="utf-8">
+</head>
+<script language="javascript">	
+	async function subscribe() {
+  		let response = await fetch("/data");
+	
+		let message = await response.text();
+		document.getElementById("display").innerHTML = message;
+		
+		await new Promise(resolve => setTimeout(resolve, 1000));
+		await subscribe();
+  	}
+
+	function stream() {
+		let received = false;
+		let source = new EventSource("/events");
+
+		source.onmessage = function(event) {
+			received = true;
+			document.getElementById("display").innerHTML = event.data;
+		};
+
+		// If the stream never worked then fallback to polling, otherwise let the browser reconnect
+		source.onerror = function() {
+			if (!received) {
+				source.close();
+				subscribe();
+			}
+		};
+	}
+
+	if (window.EventSource) {
+		stream();
+	} else {
+		subscribe();
+	}
+
+	async function control(action) {
+		let token = localStorage.getItem("token");
+		if (token == null) {
+			token = prompt("Remote control token");
+			if (token == null) {
+				return;
+			}
+			localStorage.setItem("token", token);
+		}
+
+		let response = await fetch("/api/control/" + action, {
+			method: "POST",
+			headers: {"Authorization": "Bearer " + token}
+		});
+
+		if (response.status == 401) {
+			localStorage.removeItem("token");
+		}
+		if (!response.ok) {
+			alert(await response.text());
+		}
+	}
+
+</script>
+<body style="background-color:black; color:white">
+	<div>
+		<button onclick="control('pause')">Pause</button>
+		<button onclick="control('skip-5s')">+5 Seconds</button>
+		<button onclick="control('skip-1m')">+1 Minute</button>
+		<button onclick="control('skip-lap')">+1 Lap</button>
+		<button onclick="control('session-start')">Session Start</button>
+		<button onclick="control('slow-down')">Slower</button>
+		<button onclick="control('speed-up')">Faster</button>
+		<button onclick="control('mute')">Mute Radio</button>
+		<button onclick="control('gap')">Toggle Gap</button>
+	</div>
+	<div>
+		<pre id="display"></pre>
+	</div>
+</body>
+</html>`
@@ -0,0 +1,146 @@
+// F1Gopher-CmdLine - Copyright (C) 2022 f1gopher
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package webServer
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStreamHTML(t *testing.T) {
+	web := New(nil, "")
+	web.Publish("first\nframe")
+
+	server := httptest.NewServer(web.router)
+	defer server.Close()
+
+	response, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	if contentType := response.Header.Get("Content-Type"); contentType != "text/event-stream" {
+		t.Errorf("unexpected content type: %s", contentType)
+	}
+
+	reader := bufio.NewReader(response.Body)
+	readFrame := func() string {
+		frame := ""
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatal(err)
+			}
+			if line == "\n" {
+				return frame
+			}
+			frame += line
+		}
+	}
+
+	if frame := readFrame(); frame != "data: first\ndata: frame\n" {
+		t.Errorf("unexpected first frame: %q", frame)
+	}
+
+	// Unchanged content isn't resent
+	web.Publish("first\nframe")
+	web.Publish("second")
+	if frame := readFrame(); frame != "data: second\n" {
+		t.Errorf("unexpected second frame: %q", frame)
+	}
+}
+
+func TestSessionHandler(t *testing.T) {
+	web := New(nil, "")
+
+	recorder := httptest.NewRecorder()
+	web.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/timing", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected not found without a session, got %d", recorder.Code)
+	}
+
+	web.SetSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	recorder = httptest.NewRecorder()
+	web.router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/timing", nil))
+	if recorder.Body.String() != "/api/timing" {
+		t.Errorf("expected the request to be passed to the session, got %q", recorder.Body.String())
+	}
+
+	web.Publish("session")
+	web.SetSession(nil)
+	if web.currentHTML() != NoSessionHTML {
+		t.Errorf("expected the no session page, got %q", web.currentHTML())
+	}
+}
+
+func TestStartReportsBindErrors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	web := New([]string{listener.Addr().String(), "127.0.0.1:0"}, "")
+	errs := web.Start()
+	defer web.Shutdown()
+
+	if len(errs) != 1 {
+		t.Errorf("expected one bind error, got %v", errs)
+	}
+	if len(web.servers) != 1 {
+		t.Errorf("expected one server to be running, got %d", len(web.servers))
+	}
+}
+
+func TestControlAuthorization(t *testing.T) {
+	control := func(web *Server, token string) int {
+		request := httptest.NewRequest(http.MethodPost, "/api/control/pause", nil)
+		if len(token) > 0 {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		web.router.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	session := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	disabled := New(nil, "")
+	disabled.SetSession(session)
+	if code := control(disabled, "secret"); code != http.StatusForbidden {
+		t.Errorf("expected controls to be disabled without a token, got %d", code)
+	}
+
+	web := New(nil, "secret")
+	web.SetSession(session)
+	if code := control(web, ""); code != http.StatusUnauthorized {
+		t.Errorf("expected missing token to be rejected, got %d", code)
+	}
+	if code := control(web, "wrong"); code != http.StatusUnauthorized {
+		t.Errorf("expected wrong token to be rejected, got %d", code)
+	}
+	if code := control(web, "secret"); code != http.StatusNoContent {
+		t.Errorf("expected the control to be run, got %d", code)
+	}
+}